@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	grpcserver "github.com/traceloop-ai/traceloop/server/grpc"
+)
+
+// defaultAdminServer is the default address of a traceloop server's gRPC
+// port (the same one the OTLP TraceService listens on), used by the
+// admin subcommands when --server isn't given.
+const defaultAdminServer = "localhost:4317"
+
+// adminClient dials addr and returns a client for the admin API,
+// along with a closer the caller must invoke once done with it.
+func adminClient(addr string) (grpcserver.AdminServiceClient, func() error, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return grpcserver.NewAdminServiceClient(conn), conn.Close, nil
+}
+
+// serverAddr reads the --server flag shared by every admin subcommand.
+func serverAddr(cmd *cobra.Command) (string, error) {
+	return cmd.Flags().GetString("server")
+}
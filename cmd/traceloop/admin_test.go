@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	grpcserver "github.com/traceloop-ai/traceloop/server/grpc"
+	"github.com/traceloop-ai/traceloop/server/storage"
+)
+
+// startTestAdminServer starts an in-process gRPC server exposing the
+// admin API over a BadgerStore rooted at a temporary directory, and
+// returns a connected client plus a cleanup func.
+func startTestAdminServer(t *testing.T) (grpcserver.AdminServiceClient, func()) {
+	t.Helper()
+
+	dataDir, err := os.MkdirTemp("", "traceloop-admin-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+
+	store, err := storage.NewBadgerStore(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create badger store: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	grpcSrv := grpc.NewServer()
+	grpcserver.RegisterAdminServiceServer(grpcSrv, grpcserver.NewAdminServer(store))
+	go grpcSrv.Serve(lis)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial test server: %v", err)
+	}
+
+	cleanup := func() {
+		conn.Close()
+		grpcSrv.Stop()
+		store.Close()
+		os.RemoveAll(dataDir)
+	}
+
+	return grpcserver.NewAdminServiceClient(conn), cleanup
+}
+
+func TestAdminService_TracesAndStats(t *testing.T) {
+	client, cleanup := startTestAdminServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if _, err := client.GetTrace(ctx, mustStruct(t, map[string]interface{}{"id": "does-not-exist"})); err == nil {
+		t.Fatalf("expected an error for a missing trace")
+	}
+
+	listResp, err := client.ListTraces(ctx, mustStruct(t, map[string]interface{}{"limit": float64(10)}))
+	if err != nil {
+		t.Fatalf("ListTraces failed: %v", err)
+	}
+	if traces, ok := listResp.AsMap()["traces"].([]interface{}); ok && len(traces) != 0 {
+		t.Errorf("expected no traces in a fresh store, got %v", traces)
+	}
+
+	statsResp, err := client.Stats(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if got := statsResp.AsMap()["total_traces"]; got != float64(0) {
+		t.Errorf("expected 0 total_traces, got %v", got)
+	}
+
+	deleteResp, err := client.DeleteTraces(ctx, mustStruct(t, map[string]interface{}{
+		"older_than": "2100-01-01T00:00:00Z",
+	}))
+	if err != nil {
+		t.Fatalf("DeleteTraces failed: %v", err)
+	}
+	if got := deleteResp.AsMap()["deleted"]; got != float64(0) {
+		t.Errorf("expected 0 traces deleted from an empty store, got %v", got)
+	}
+
+	if _, err := client.Compact(ctx, &emptypb.Empty{}); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+}
+
+func TestAdminService_BackupRestoreRoundTrip(t *testing.T) {
+	client, cleanup := startTestAdminServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	backupStream, err := client.Backup(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	var backup bytes.Buffer
+	for {
+		chunk, err := backupStream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to receive backup chunk: %v", err)
+		}
+		backup.Write(chunk.GetValue())
+	}
+
+	restoreStream, err := client.Restore(ctx)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if err := restoreStream.Send(wrapperspb.Bytes(backup.Bytes())); err != nil {
+		t.Fatalf("failed to send restore chunk: %v", err)
+	}
+	if _, err := restoreStream.CloseAndRecv(); err != nil {
+		t.Fatalf("failed to complete restore: %v", err)
+	}
+}
+
+func mustStruct(t *testing.T, fields map[string]interface{}) *structpb.Struct {
+	t.Helper()
+	s, err := structpb.NewStruct(fields)
+	if err != nil {
+		t.Fatalf("failed to build struct: %v", err)
+	}
+	return s
+}
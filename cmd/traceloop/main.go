@@ -23,7 +23,14 @@ and performance analytics for production AI systems.`,
 		Version: fmt.Sprintf("%s (%s)", version, commit),
 	}
 
+	rootCmd.PersistentFlags().String("server", defaultAdminServer, "Address of the traceloop server's gRPC port")
+
 	rootCmd.AddCommand(serverCmd())
+	rootCmd.AddCommand(backupCmd())
+	rootCmd.AddCommand(restoreCmd())
+	rootCmd.AddCommand(tracesCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(compactCmd())
 	rootCmd.AddCommand(versionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -34,6 +41,14 @@ and performance analytics for production AI systems.`,
 func serverCmd() *cobra.Command {
 	var port int
 	var host string
+	var otlpGRPCPort int
+	var otlpHTTPPort int
+	var storageDriver string
+	var storageDSN string
+	var configFile string
+	var raftDir string
+	var raftPort int
+	var clusterPeers []string
 
 	cmd := &cobra.Command{
 		Use:   "server",
@@ -41,8 +56,16 @@ func serverCmd() *cobra.Command {
 		Long:  "Start the traceloop server to collect and serve trace data",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			config := server.Config{
-				Host: host,
-				Port: port,
+				Host:         host,
+				Port:         port,
+				OTLPGRPCPort: otlpGRPCPort,
+				OTLPHTTPPort: otlpHTTPPort,
+				Storage:      storageDriver,
+				StorageDSN:   storageDSN,
+				ConfigFile:   configFile,
+				RaftDir:      raftDir,
+				RaftPort:     raftPort,
+				ClusterPeers: clusterPeers,
 			}
 			return server.Start(config)
 		},
@@ -50,6 +73,14 @@ func serverCmd() *cobra.Command {
 
 	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to run the server on")
 	cmd.Flags().StringVar(&host, "host", "localhost", "Host to bind the server to")
+	cmd.Flags().IntVar(&otlpGRPCPort, "otlp-grpc-port", 4317, "Port for the OTLP gRPC TraceService receiver")
+	cmd.Flags().IntVar(&otlpHTTPPort, "otlp-http-port", 4318, "Port for the OTLP/HTTP trace receiver")
+	cmd.Flags().StringVar(&storageDriver, "storage", "badger", "Storage driver to use (badger, elasticsearch, clickhouse)")
+	cmd.Flags().StringVar(&storageDSN, "storage-dsn", "./data", "Connection string/path passed to the storage driver")
+	cmd.Flags().StringVar(&configFile, "config", "", "Path to a YAML config file (sampling, etc.)")
+	cmd.Flags().StringVar(&raftDir, "raft-dir", "", "Enable clustered mode, storing Raft state under this directory")
+	cmd.Flags().IntVar(&raftPort, "raft-port", 9000, "Port the Raft transport binds to")
+	cmd.Flags().StringSliceVar(&clusterPeers, "cluster-peers", nil, "Raft addresses of an existing cluster to join (empty bootstraps a new cluster)")
 
 	return cmd
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// parseS3URL splits an "s3://bucket/key" URL into its bucket and key.
+func parseS3URL(s3URL string) (bucket, key string, err error) {
+	u, err := url.Parse(s3URL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid s3 URL %q: %w", s3URL, err)
+	}
+	bucket = u.Host
+	key = strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != "s3" || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("expected an s3://bucket/key URL, got %q", s3URL)
+	}
+	return bucket, key, nil
+}
+
+// s3Client builds an S3 client from the default AWS credential chain
+// (environment, shared config, EC2/ECS/EKS role).
+func s3Client(ctx context.Context) (*s3.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return s3.NewFromConfig(cfg), nil
+}
+
+// uploadToS3 streams r to s3URL as a multipart upload, so a backup of
+// any size can be sent without buffering it in memory first.
+func uploadToS3(ctx context.Context, s3URL string, r io.Reader) error {
+	bucket, key, err := parseS3URL(s3URL)
+	if err != nil {
+		return err
+	}
+
+	client, err := s3Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = manager.NewUploader(client).Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload to s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// downloadFromS3 returns a reader over s3URL's contents.
+func downloadFromS3(ctx context.Context, s3URL string) (io.ReadCloser, error) {
+	bucket, key, err := parseS3URL(s3URL)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", bucket, key, err)
+	}
+	return out.Body, nil
+}
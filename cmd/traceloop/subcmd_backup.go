@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func backupCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up a traceloop server's storage to a file or S3 URL",
+		Long:  "Stream the storage backend's native backup format to a local file or an s3:// URL. Only the badger storage driver supports this.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			stream, err := client.Backup(context.Background(), &emptypb.Empty{})
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+
+			w, closeW, err := openBackupDest(cmd.Context(), output)
+			if err != nil {
+				return err
+			}
+
+			var total int64
+			for {
+				chunk, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					closeW(err)
+					return fmt.Errorf("failed to receive backup chunk: %w", err)
+				}
+				n, err := w.Write(chunk.GetValue())
+				total += int64(n)
+				if err != nil {
+					closeW(err)
+					return fmt.Errorf("failed to write %s: %w", output, err)
+				}
+			}
+
+			if err := closeW(nil); err != nil {
+				return fmt.Errorf("failed to finish writing %s: %w", output, err)
+			}
+
+			if output != "-" {
+				fmt.Printf("Backed up %d bytes to %s\n", total, output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "backup.db", "File or s3:// URL to write the backup to, or - for stdout")
+
+	return cmd
+}
+
+// openBackupDest opens dest for writing a backup stream, returning a
+// writer and a closer that must be called exactly once when done
+// (with the error that aborted the stream, or nil on success) to
+// release any underlying file handle or in-flight S3 upload.
+//
+// dest is one of:
+//   - "-", meaning stdout
+//   - an "s3://bucket/key" URL, uploaded via a streaming multipart upload
+//   - a local file path
+func openBackupDest(ctx context.Context, dest string) (io.Writer, func(error) error, error) {
+	switch {
+	case dest == "-":
+		return os.Stdout, func(error) error { return nil }, nil
+
+	case strings.HasPrefix(dest, "s3://"):
+		pr, pw := io.Pipe()
+		uploadErr := make(chan error, 1)
+		go func() { uploadErr <- uploadToS3(ctx, dest, pr) }()
+
+		return pw, func(streamErr error) error {
+			if streamErr != nil {
+				pw.CloseWithError(streamErr)
+				<-uploadErr
+				return nil
+			}
+			pw.Close()
+			return <-uploadErr
+		}, nil
+
+	default:
+		f, err := os.Create(dest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create %s: %w", dest, err)
+		}
+		return f, func(error) error { return f.Close() }, nil
+	}
+}
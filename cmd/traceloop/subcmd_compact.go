@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func compactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compact",
+		Short: "Trigger a storage compaction pass on a running server",
+		Long:  "Trigger BadgerDB's value-log garbage collection. Only the badger storage driver supports this.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			if _, err := client.Compact(context.Background(), &emptypb.Empty{}); err != nil {
+				return fmt.Errorf("failed to compact storage: %w", err)
+			}
+
+			fmt.Println("Compaction complete")
+			return nil
+		},
+	}
+}
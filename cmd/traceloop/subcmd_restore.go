@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func restoreCmd() *cobra.Command {
+	var input string
+	var chunkSize int
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a traceloop server's storage from a backup file or S3 URL",
+		Long:  "Stream a file or s3:// URL produced by \"traceloop backup\" to the server, replacing its current storage contents. Only the badger storage driver supports this.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			r, closeR, err := openBackupSrc(cmd.Context(), input)
+			if err != nil {
+				return err
+			}
+			defer closeR()
+
+			stream, err := client.Restore(context.Background())
+			if err != nil {
+				return fmt.Errorf("failed to start restore: %w", err)
+			}
+
+			var total int64
+			buf := make([]byte, chunkSize)
+			for {
+				n, err := r.Read(buf)
+				if n > 0 {
+					chunk := wrapperspb.Bytes(append([]byte(nil), buf[:n]...))
+					if sendErr := stream.Send(chunk); sendErr != nil {
+						return fmt.Errorf("failed to send restore chunk: %w", sendErr)
+					}
+					total += int64(n)
+				}
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", input, err)
+				}
+			}
+
+			if _, err := stream.CloseAndRecv(); err != nil {
+				return fmt.Errorf("failed to complete restore: %w", err)
+			}
+
+			fmt.Printf("Restored %d bytes from %s\n", total, input)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "backup.db", "Backup file or s3:// URL to restore from")
+	cmd.Flags().IntVar(&chunkSize, "chunk-size", 64*1024, "Bytes to send per restore chunk")
+
+	return cmd
+}
+
+// openBackupSrc opens src for reading a backup stream, returning a
+// reader and a closer. src is either an "s3://bucket/key" URL or a
+// local file path.
+func openBackupSrc(ctx context.Context, src string) (io.Reader, func() error, error) {
+	if strings.HasPrefix(src, "s3://") {
+		body, err := downloadFromS3(ctx, src)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, body.Close, nil
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func statsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show storage statistics for a running server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			resp, err := client.Stats(context.Background(), &emptypb.Empty{})
+			if err != nil {
+				return fmt.Errorf("failed to get stats: %w", err)
+			}
+
+			return printJSON(resp.AsMap())
+		},
+	}
+}
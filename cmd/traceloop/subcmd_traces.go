@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// tracesCmd groups the trace-management subcommands under
+// "traceloop traces".
+func tracesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "traces",
+		Short: "List, inspect, or delete traces on a running server",
+	}
+
+	cmd.AddCommand(tracesListCmd())
+	cmd.AddCommand(tracesGetCmd())
+	cmd.AddCommand(tracesDeleteCmd())
+
+	return cmd
+}
+
+func tracesListCmd() *cobra.Command {
+	var service string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List traces matching a filter",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			req, err := structpb.NewStruct(map[string]interface{}{
+				"service": service,
+				"limit":   float64(limit),
+			})
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.ListTraces(context.Background(), req)
+			if err != nil {
+				return fmt.Errorf("failed to list traces: %w", err)
+			}
+
+			return printJSON(resp.AsMap()["traces"])
+		},
+	}
+
+	cmd.Flags().StringVar(&service, "service", "", "Only list traces from this service")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of traces to list")
+
+	return cmd
+}
+
+func tracesGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <trace-id>",
+		Short: "Get a single trace by ID",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			req, err := structpb.NewStruct(map[string]interface{}{"id": args[0]})
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.GetTrace(context.Background(), req)
+			if err != nil {
+				return fmt.Errorf("failed to get trace %s: %w", args[0], err)
+			}
+
+			return printJSON(resp.AsMap())
+		},
+	}
+
+	return cmd
+}
+
+func tracesDeleteCmd() *cobra.Command {
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete traces older than a given age",
+		Long:  "Delete every trace whose start_time is older than --older-than (a Go duration, plus a day unit, e.g. 7d or 168h).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than %q: %w", olderThan, err)
+			}
+
+			addr, err := serverAddr(cmd)
+			if err != nil {
+				return err
+			}
+			client, closeConn, err := adminClient(addr)
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			defer closeConn()
+
+			req, err := structpb.NewStruct(map[string]interface{}{
+				"older_than": time.Now().Add(-age).Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.DeleteTraces(context.Background(), req)
+			if err != nil {
+				return fmt.Errorf("failed to delete traces: %w", err)
+			}
+
+			fmt.Printf("Deleted %v traces\n", resp.AsMap()["deleted"])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "7d", "Delete traces older than this duration (e.g. 7d or 168h)")
+
+	return cmd
+}
+
+// parseAge parses a duration the way time.ParseDuration does, plus a
+// trailing "d" unit for whole days (e.g. "7d"), since Go's duration
+// syntax has no day unit of its own.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid day count %q", days)
+		}
+		return time.Duration(n * 24 * float64(time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+
+	"github.com/traceloop-ai/traceloop/server/storage"
+)
+
+const applyTimeout = 10 * time.Second
+
+// Config configures a clustered deployment.
+type Config struct {
+	// NodeID uniquely identifies this node in the Raft configuration.
+	NodeID string
+	// RaftAddr is the host:port this node's Raft transport binds to.
+	RaftAddr string
+	// RaftDir holds the node's Raft log, stable store and snapshots.
+	RaftDir string
+	// APIAddr is this node's HTTP API address, advertised to peers so
+	// they can forward trace writes here when this node is the leader.
+	APIAddr string
+	// Bootstrap starts a brand new single-node cluster. Every other node
+	// must join it afterwards through the /cluster/join admin endpoint.
+	Bootstrap bool
+}
+
+// Cluster wraps a Raft group whose FSM applies trace writes to a
+// BadgerStore, so a write is only acknowledged once it has been
+// replicated to a quorum of the cluster.
+type Cluster struct {
+	raft *raft.Raft
+	fsm  *FSM
+}
+
+// New starts (or rejoins) a Raft node for store.
+func New(cfg Config, store *storage.BadgerStore) (*Cluster, error) {
+	if err := os.MkdirAll(cfg.RaftDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create raft dir: %w", err)
+	}
+
+	fsm := NewFSM(store, cfg.RaftAddr, cfg.APIAddr)
+
+	raftCfg := raft.DefaultConfig()
+	raftCfg.LocalID = raft.ServerID(cfg.NodeID)
+
+	addr, err := net.ResolveTCPAddr("tcp", cfg.RaftAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve raft address: %w", err)
+	}
+	transport, err := raft.NewTCPTransport(cfg.RaftAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raft transport: %w", err)
+	}
+
+	snapshots, err := raft.NewFileSnapshotStore(cfg.RaftDir, 2, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot store: %w", err)
+	}
+
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.RaftDir, "raft-log.bolt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raft log store: %w", err)
+	}
+	stableStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.RaftDir, "raft-stable.bolt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raft stable store: %w", err)
+	}
+
+	r, err := raft.NewRaft(raftCfg, fsm, logStore, stableStore, snapshots, transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raft node: %w", err)
+	}
+
+	if cfg.Bootstrap {
+		future := r.BootstrapCluster(raft.Configuration{
+			Servers: []raft.Server{{ID: raftCfg.LocalID, Address: transport.LocalAddr()}},
+		})
+		if err := future.Error(); err != nil && err != raft.ErrCantBootstrap {
+			return nil, fmt.Errorf("failed to bootstrap raft cluster: %w", err)
+		}
+	}
+
+	return &Cluster{raft: r, fsm: fsm}, nil
+}
+
+// Join adds nodeID, reachable at raftAddr, as a Raft voter, then
+// replicates its API address to the rest of the cluster so every node
+// can forward trace writes to whichever one is currently the leader.
+// Must be called on the current leader.
+func (c *Cluster) Join(nodeID, raftAddr, apiAddr string) error {
+	if c.raft.State() != raft.Leader {
+		return fmt.Errorf("not the raft leader")
+	}
+
+	future := c.raft.AddVoter(raft.ServerID(nodeID), raft.ServerAddress(raftAddr), 0, applyTimeout)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to add voter: %w", err)
+	}
+
+	return c.applyCommand(command{Op: opRegisterPeer, RaftAddr: raftAddr, APIAddr: apiAddr})
+}
+
+// Leave removes nodeID from the cluster. Must be called on the current
+// leader.
+func (c *Cluster) Leave(nodeID string) error {
+	if c.raft.State() != raft.Leader {
+		return fmt.Errorf("not the raft leader")
+	}
+
+	future := c.raft.RemoveServer(raft.ServerID(nodeID), 0, applyTimeout)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to remove server: %w", err)
+	}
+	return nil
+}
+
+// Apply proposes trace as a Raft log entry. It returns once the write
+// has been replicated to a quorum of the cluster and applied to this
+// node's FSM. Must be called on the current leader.
+func (c *Cluster) Apply(ctx context.Context, trace map[string]interface{}) error {
+	return c.applyCommand(command{Op: opStoreTrace, Trace: trace})
+}
+
+func (c *Cluster) applyCommand(cmd command) error {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to encode raft command: %w", err)
+	}
+
+	future := c.raft.Apply(data, applyTimeout)
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to apply raft command: %w", err)
+	}
+	if err, ok := future.Response().(error); ok && err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsLeader reports whether this node is currently the Raft leader.
+func (c *Cluster) IsLeader() bool {
+	return c.raft.State() == raft.Leader
+}
+
+// LeaderAPIAddr returns the HTTP API address of the current Raft
+// leader, if known, so a follower can forward writes there.
+func (c *Cluster) LeaderAPIAddr() (string, bool) {
+	leaderAddr := c.raft.Leader()
+	if leaderAddr == "" {
+		return "", false
+	}
+	return c.fsm.PeerAPIAddr(string(leaderAddr))
+}
+
+// Shutdown gracefully stops the Raft node.
+func (c *Cluster) Shutdown() error {
+	return c.raft.Shutdown().Error()
+}
@@ -0,0 +1,175 @@
+// Package cluster extends traceloop with an optional Raft-based
+// clustered mode: trace writes are applied through a replicated log so
+// any node in the cluster can accept traces, and BadgerDB snapshots keep
+// followers from having to replay the full write history to catch up.
+package cluster
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+
+	"github.com/traceloop-ai/traceloop/server/storage"
+)
+
+const (
+	opStoreTrace   = "store_trace"
+	opRegisterPeer = "register_peer"
+)
+
+// command is the payload applied to every Raft log entry.
+type command struct {
+	Op string `json:"op"`
+
+	// Set when Op == opStoreTrace.
+	Trace map[string]interface{} `json:"trace,omitempty"`
+
+	// Set when Op == opRegisterPeer.
+	RaftAddr string `json:"raft_addr,omitempty"`
+	APIAddr  string `json:"api_addr,omitempty"`
+}
+
+// FSM applies committed Raft log entries to the underlying BadgerDB
+// store, and tracks which API address serves each Raft peer so ingest
+// handlers on a follower can forward writes to the current leader.
+type FSM struct {
+	store *storage.BadgerStore
+
+	mu        sync.RWMutex
+	peerAddrs map[string]string // raft addr -> api addr
+}
+
+// NewFSM creates an FSM backed by store. selfRaftAddr/selfAPIAddr seed
+// this node's own address mapping; peers learn it via an
+// opRegisterPeer log entry applied when they join.
+func NewFSM(store *storage.BadgerStore, selfRaftAddr, selfAPIAddr string) *FSM {
+	return &FSM{
+		store:     store,
+		peerAddrs: map[string]string{selfRaftAddr: selfAPIAddr},
+	}
+}
+
+// Apply implements raft.FSM.
+func (f *FSM) Apply(log *raft.Log) interface{} {
+	var cmd command
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return fmt.Errorf("failed to decode raft log entry: %w", err)
+	}
+
+	switch cmd.Op {
+	case opStoreTrace:
+		return f.store.StoreTrace(context.Background(), cmd.Trace)
+	case opRegisterPeer:
+		f.mu.Lock()
+		f.peerAddrs[cmd.RaftAddr] = cmd.APIAddr
+		f.mu.Unlock()
+		return nil
+	default:
+		return fmt.Errorf("unknown raft command %q", cmd.Op)
+	}
+}
+
+// PeerAPIAddr returns the API address advertised by the peer listening
+// on raftAddr, if known.
+func (f *FSM) PeerAPIAddr(raftAddr string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	addr, ok := f.peerAddrs[raftAddr]
+	return addr, ok
+}
+
+// Snapshot implements raft.FSM, serializing peerAddrs alongside the
+// entire BadgerDB keyspace (in its native backup format) so that a node
+// catching up via InstallSnapshot rather than full log replay still
+// learns every peer's API address, not just the ones it happens to
+// already know.
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.RLock()
+	peerAddrs := make(map[string]string, len(f.peerAddrs))
+	for raftAddr, apiAddr := range f.peerAddrs {
+		peerAddrs[raftAddr] = apiAddr
+	}
+	f.mu.RUnlock()
+
+	return &fsmSnapshot{store: f.store, peerAddrs: peerAddrs}, nil
+}
+
+// Restore implements raft.FSM, replacing peerAddrs and the store's
+// contents with a snapshot produced by Snapshot/Persist.
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	peerAddrs, err := readPeerAddrs(rc)
+	if err != nil {
+		return fmt.Errorf("failed to read peer addresses from snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	f.peerAddrs = peerAddrs
+	f.mu.Unlock()
+
+	return f.store.Restore(rc)
+}
+
+type fsmSnapshot struct {
+	store     *storage.BadgerStore
+	peerAddrs map[string]string
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := writePeerAddrs(sink, s.peerAddrs); err != nil {
+		sink.Cancel()
+		return fmt.Errorf("failed to persist peer addresses: %w", err)
+	}
+
+	if err := s.store.Backup(sink); err != nil {
+		sink.Cancel()
+		return fmt.Errorf("failed to persist badger snapshot: %w", err)
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
+
+// writePeerAddrs writes a length-prefixed JSON encoding of peerAddrs to
+// w, ahead of the BadgerDB backup stream, so readPeerAddrs can read
+// exactly that many bytes back out of Restore's reader before handing
+// the rest of the stream to the store's own restore format.
+func writePeerAddrs(w io.Writer, peerAddrs map[string]string) error {
+	data, err := json.Marshal(peerAddrs)
+	if err != nil {
+		return fmt.Errorf("failed to encode peer addresses: %w", err)
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readPeerAddrs reads the length-prefixed JSON payload written by
+// writePeerAddrs off the front of r, leaving r positioned at the start
+// of the BadgerDB backup stream that follows it.
+func readPeerAddrs(r io.Reader) (map[string]string, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	peerAddrs := make(map[string]string)
+	if err := json.Unmarshal(data, &peerAddrs); err != nil {
+		return nil, fmt.Errorf("failed to decode peer addresses: %w", err)
+	}
+	return peerAddrs, nil
+}
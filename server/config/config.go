@@ -0,0 +1,39 @@
+// Package config loads traceloop's optional YAML config file, currently
+// used for the sampling subsystem and self-instrumentation.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/traceloop-ai/traceloop/server/sampling"
+	"github.com/traceloop-ai/traceloop/server/telemetry"
+)
+
+// Config is the top-level shape of the YAML config file.
+type Config struct {
+	Sampling sampling.Config  `yaml:"sampling"`
+	Trace    telemetry.Config `yaml:"trace"`
+}
+
+// Load reads and parses the YAML config file at path. An empty path
+// returns a zero-value Config (sampling disabled).
+func Load(path string) (*Config, error) {
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return &cfg, nil
+}
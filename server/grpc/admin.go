@@ -0,0 +1,326 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// adminServiceName is the fully qualified gRPC service name for the
+// admin API, namespaced the same way the OTLP TraceService is.
+const adminServiceName = "traceloop.admin.v1.AdminService"
+
+// AdminServiceServer is implemented by the traceloop server to expose
+// operational commands (backup, restore, trace management) to the
+// traceloop CLI over gRPC, so admin commands work against any running
+// instance without opening its storage directly. Messages reuse the
+// well-known structpb/wrapperspb types rather than a hand-maintained
+// .proto, mirroring the dynamic map[string]interface{} trace
+// representation already used throughout the storage and otlp packages.
+type AdminServiceServer interface {
+	// Backup streams the storage backend's native backup format to the
+	// client.
+	Backup(*emptypb.Empty, AdminService_BackupServer) error
+	// Restore replaces storage's contents with a stream produced by
+	// Backup.
+	Restore(AdminService_RestoreServer) error
+	// ListTraces returns traces matching a filter, keyed the same way as
+	// the HTTP API: {"service", "start", "end", "attr", "limit"} in,
+	// {"traces": [...]} out.
+	ListTraces(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	// GetTrace returns a single trace by {"id": "..."}.
+	GetTrace(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	// DeleteTraces removes every trace older than {"older_than": RFC3339
+	// timestamp}, returning {"deleted": n}.
+	DeleteTraces(context.Context, *structpb.Struct) (*structpb.Struct, error)
+	// Stats returns the storage backend's statistics (total_traces,
+	// total_spans, storage_size). Unlike the HTTP /api/v1/stats
+	// endpoint, it does not include the sampling_sampled and
+	// sampling_dropped counters.
+	Stats(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	// Compact triggers a storage-specific compaction pass (BadgerDB value
+	// log GC). Backends that don't support one return an error.
+	Compact(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+}
+
+// AdminService_BackupServer is the server-side stream of backup chunks.
+type AdminService_BackupServer interface {
+	Send(*wrapperspb.BytesValue) error
+	grpc.ServerStream
+}
+
+// AdminService_RestoreServer is the server-side stream of restore
+// chunks, acknowledged with a single response once the client closes
+// the stream.
+type AdminService_RestoreServer interface {
+	Recv() (*wrapperspb.BytesValue, error)
+	SendAndClose(*emptypb.Empty) error
+	grpc.ServerStream
+}
+
+type adminServiceBackupServer struct {
+	grpc.ServerStream
+}
+
+func (s *adminServiceBackupServer) Send(chunk *wrapperspb.BytesValue) error {
+	return s.ServerStream.SendMsg(chunk)
+}
+
+type adminServiceRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (s *adminServiceRestoreServer) Recv() (*wrapperspb.BytesValue, error) {
+	chunk := new(wrapperspb.BytesValue)
+	if err := s.ServerStream.RecvMsg(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (s *adminServiceRestoreServer) SendAndClose(resp *emptypb.Empty) error {
+	return s.ServerStream.SendMsg(resp)
+}
+
+func adminServiceBackupHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(emptypb.Empty)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(AdminServiceServer).Backup(req, &adminServiceBackupServer{stream})
+}
+
+func adminServiceRestoreHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServiceServer).Restore(&adminServiceRestoreServer{stream})
+}
+
+func adminServiceListTracesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(structpb.Struct)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListTraces(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: adminServiceName + "/ListTraces"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListTraces(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func adminServiceGetTraceHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(structpb.Struct)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetTrace(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: adminServiceName + "/GetTrace"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetTrace(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func adminServiceDeleteTracesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(structpb.Struct)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).DeleteTraces(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: adminServiceName + "/DeleteTraces"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).DeleteTraces(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func adminServiceStatsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(emptypb.Empty)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).Stats(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: adminServiceName + "/Stats"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).Stats(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func adminServiceCompactHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(emptypb.Empty)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).Compact(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: adminServiceName + "/Compact"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).Compact(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// adminServiceDesc wires AdminServiceServer into a grpc.Server, the same
+// way a protoc-gen-go-grpc ServiceDesc would, but maintained by hand
+// since this service has no .proto of its own.
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: adminServiceName,
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "ListTraces", Handler: adminServiceListTracesHandler},
+		{MethodName: "GetTrace", Handler: adminServiceGetTraceHandler},
+		{MethodName: "DeleteTraces", Handler: adminServiceDeleteTracesHandler},
+		{MethodName: "Stats", Handler: adminServiceStatsHandler},
+		{MethodName: "Compact", Handler: adminServiceCompactHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Backup", Handler: adminServiceBackupHandler, ServerStreams: true},
+		{StreamName: "Restore", Handler: adminServiceRestoreHandler, ClientStreams: true},
+	},
+}
+
+// RegisterAdminServiceServer registers srv with s.
+func RegisterAdminServiceServer(s *grpc.Server, srv AdminServiceServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+// AdminServiceClient is the client side of AdminServiceServer, used by
+// the traceloop CLI's admin subcommands.
+type AdminServiceClient interface {
+	Backup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (AdminService_BackupClient, error)
+	Restore(ctx context.Context, opts ...grpc.CallOption) (AdminService_RestoreClient, error)
+	ListTraces(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error)
+	GetTrace(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error)
+	DeleteTraces(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error)
+	Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*structpb.Struct, error)
+	Compact(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+// AdminService_BackupClient is the client-side stream of backup chunks.
+type AdminService_BackupClient interface {
+	Recv() (*wrapperspb.BytesValue, error)
+	grpc.ClientStream
+}
+
+// AdminService_RestoreClient is the client-side stream of restore
+// chunks.
+type AdminService_RestoreClient interface {
+	Send(*wrapperspb.BytesValue) error
+	CloseAndRecv() (*emptypb.Empty, error)
+	grpc.ClientStream
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewAdminServiceClient creates a client for the admin API on cc.
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc: cc}
+}
+
+func (c *adminServiceClient) Backup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (AdminService_BackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &adminServiceDesc.Streams[0], "/"+adminServiceName+"/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminServiceBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type adminServiceBackupClient struct {
+	grpc.ClientStream
+}
+
+func (c *adminServiceBackupClient) Recv() (*wrapperspb.BytesValue, error) {
+	chunk := new(wrapperspb.BytesValue)
+	if err := c.ClientStream.RecvMsg(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (c *adminServiceClient) Restore(ctx context.Context, opts ...grpc.CallOption) (AdminService_RestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &adminServiceDesc.Streams[1], "/"+adminServiceName+"/Restore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &adminServiceRestoreClient{stream}, nil
+}
+
+type adminServiceRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (c *adminServiceRestoreClient) Send(chunk *wrapperspb.BytesValue) error {
+	return c.ClientStream.SendMsg(chunk)
+}
+
+func (c *adminServiceRestoreClient) CloseAndRecv() (*emptypb.Empty, error) {
+	if err := c.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	resp := new(emptypb.Empty)
+	if err := c.ClientStream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *adminServiceClient) ListTraces(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+adminServiceName+"/ListTraces", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetTrace(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+adminServiceName+"/GetTrace", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) DeleteTraces(ctx context.Context, in *structpb.Struct, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+adminServiceName+"/DeleteTraces", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.cc.Invoke(ctx, "/"+adminServiceName+"/Stats", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) Compact(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	if err := c.cc.Invoke(ctx, "/"+adminServiceName+"/Compact", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/traceloop-ai/traceloop/server/storage"
+)
+
+// backupChunkSize is the maximum size of each BytesValue sent by Backup.
+// Restore accepts chunks of any size.
+const backupChunkSize = 64 * 1024
+
+// adminServer implements AdminServiceServer against a storage.Store.
+// Backup, Restore and Compact are only meaningful for BadgerDB, since
+// they rely on its native backup format and value-log GC; other
+// backends are already external shared services that manage their own
+// durability and compaction.
+type adminServer struct {
+	store storage.Store
+}
+
+// NewAdminServer creates the admin API implementation backed by store.
+func NewAdminServer(store storage.Store) AdminServiceServer {
+	return &adminServer{store: store}
+}
+
+func (s *adminServer) badgerStore() (*storage.BadgerStore, error) {
+	badgerStore, ok := storage.Unwrap(s.store).(*storage.BadgerStore)
+	if !ok {
+		return nil, fmt.Errorf("this command requires the badger storage driver")
+	}
+	return badgerStore, nil
+}
+
+func (s *adminServer) Backup(_ *emptypb.Empty, stream AdminService_BackupServer) error {
+	badgerStore, err := s.badgerStore()
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(badgerStore.Backup(pw))
+	}()
+
+	buf := make([]byte, backupChunkSize)
+	for {
+		n, err := pr.Read(buf)
+		if n > 0 {
+			chunk := wrapperspb.Bytes(append([]byte(nil), buf[:n]...))
+			if sendErr := stream.Send(chunk); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read backup stream: %w", err)
+		}
+	}
+}
+
+func (s *adminServer) Restore(stream AdminService_RestoreServer) error {
+	badgerStore, err := s.badgerStore()
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- badgerStore.Restore(pr)
+	}()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			pw.Close()
+			break
+		}
+		if err != nil {
+			pw.CloseWithError(err)
+			return fmt.Errorf("failed to receive restore chunk: %w", err)
+		}
+		if _, err := pw.Write(chunk.GetValue()); err != nil {
+			return fmt.Errorf("failed to write restore chunk: %w", err)
+		}
+	}
+
+	if err := <-done; err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return stream.SendAndClose(&emptypb.Empty{})
+}
+
+func (s *adminServer) ListTraces(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	fields := in.GetFields()
+
+	query := storage.Query{
+		Service: fields["service"].GetStringValue(),
+		Limit:   int(fields["limit"].GetNumberValue()),
+	}
+	if start := fields["start"].GetStringValue(); start != "" {
+		if t, err := time.Parse(time.RFC3339, start); err == nil {
+			query.Start = t
+		}
+	}
+	if end := fields["end"].GetStringValue(); end != "" {
+		if t, err := time.Parse(time.RFC3339, end); err == nil {
+			query.End = t
+		}
+	}
+	if attr := fields["attr"].GetStructValue(); attr != nil {
+		query.Attributes = make(map[string]string, len(attr.GetFields()))
+		for k, v := range attr.GetFields() {
+			query.Attributes[k] = v.GetStringValue()
+		}
+	}
+
+	traces, err := s.store.GetTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	traceValues := make([]interface{}, len(traces))
+	for i, t := range traces {
+		traceValues[i] = t
+	}
+	return structpb.NewStruct(map[string]interface{}{"traces": traceValues})
+}
+
+func (s *adminServer) GetTrace(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	id := in.GetFields()["id"].GetStringValue()
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+
+	trace, err := s.store.GetTrace(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(trace)
+}
+
+func (s *adminServer) DeleteTraces(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	olderThanStr := in.GetFields()["older_than"].GetStringValue()
+	olderThan, err := time.Parse(time.RFC3339, olderThanStr)
+	if err != nil {
+		return nil, fmt.Errorf("older_than must be an RFC3339 timestamp: %w", err)
+	}
+
+	deleted, err := s.store.DeleteTraces(ctx, olderThan)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(map[string]interface{}{"deleted": deleted})
+}
+
+func (s *adminServer) Stats(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	stats, err := s.store.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(stats)
+}
+
+func (s *adminServer) Compact(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	badgerStore, err := s.badgerStore()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := badgerStore.RunValueLogGC(0.5); err != nil {
+		return nil, fmt.Errorf("failed to compact storage: %w", err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -5,28 +5,31 @@ import (
 	"log"
 	"net"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+
+	"github.com/traceloop-ai/traceloop/server/otlp"
+	"github.com/traceloop-ai/traceloop/server/sampling"
+	"github.com/traceloop-ai/traceloop/server/storage"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 )
 
 // Server represents the gRPC server
 type Server struct {
 	grpcServer *grpc.Server
-	store      Store
-}
-
-// Store interface for data storage
-type Store interface {
-	// Add methods that the gRPC server needs
-	Close() error
+	store      storage.Store
 }
 
-// NewServer creates a new gRPC server
-func NewServer(store Store) (*Server, error) {
-	grpcServer := grpc.NewServer()
+// NewServer creates a new gRPC server. writer persists traces accepted
+// over the OTLP TraceService (after sampler's sampling policies are
+// applied) — ordinarily store itself, or a clustered writer that routes
+// through Raft consensus and leader-forwarding.
+func NewServer(store storage.Store, writer otlp.Writer, sampler *sampling.Sampler) (*Server, error) {
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 
-	// Register services here when we implement them
-	// For now, just enable reflection for testing
+	collectortracepb.RegisterTraceServiceServer(grpcServer, otlp.NewTraceServiceServer(writer, sampler))
+	RegisterAdminServiceServer(grpcServer, NewAdminServer(store))
 	reflection.Register(grpcServer)
 
 	return &Server{
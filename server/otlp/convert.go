@@ -0,0 +1,123 @@
+// Package otlp translates OpenTelemetry Protocol data into traceloop's
+// internal trace/span representation and exposes gRPC and HTTP ingest
+// endpoints so that any OpenTelemetry SDK can push traces directly into
+// traceloop without a custom SDK.
+package otlp
+
+import (
+	"encoding/hex"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// ConvertResourceSpans flattens a ResourceSpans message into traceloop's
+// trace model, grouped by trace ID. A single ResourceSpans payload can
+// carry spans belonging to more than one trace, so callers should store
+// every entry in the returned map.
+func ConvertResourceSpans(rs *tracepb.ResourceSpans) map[string]map[string]interface{} {
+	resourceAttrs := attributesToMap(rs.GetResource().GetAttributes())
+	serviceName := serviceNameFromResource(rs.GetResource())
+
+	traces := make(map[string]map[string]interface{})
+
+	for _, ss := range rs.GetScopeSpans() {
+		for _, span := range ss.GetSpans() {
+			traceID := hex.EncodeToString(span.GetTraceId())
+			if traceID == "" {
+				continue
+			}
+
+			trace, ok := traces[traceID]
+			if !ok {
+				trace = map[string]interface{}{
+					"trace_id":   traceID,
+					"name":       span.GetName(),
+					"start_time": formatUnixNano(span.GetStartTimeUnixNano()),
+					"status":     "ok",
+					"service":    serviceName,
+					"attributes": resourceAttrs,
+					"spans":      []map[string]interface{}{},
+				}
+				traces[traceID] = trace
+			}
+
+			spans := trace["spans"].([]map[string]interface{})
+			trace["spans"] = append(spans, convertSpan(span))
+
+			if span.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+				trace["status"] = "error"
+			}
+		}
+	}
+
+	return traces
+}
+
+func convertSpan(span *tracepb.Span) map[string]interface{} {
+	status := "ok"
+	if span.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+		status = "error"
+	}
+
+	return map[string]interface{}{
+		"span_id":        hex.EncodeToString(span.GetSpanId()),
+		"parent_span_id": hex.EncodeToString(span.GetParentSpanId()),
+		"name":           span.GetName(),
+		"start_time":     formatUnixNano(span.GetStartTimeUnixNano()),
+		"end_time":       formatUnixNano(span.GetEndTimeUnixNano()),
+		"status":         status,
+		"attributes":     attributesToMap(span.GetAttributes()),
+	}
+}
+
+func serviceNameFromResource(resource *resourcepb.Resource) string {
+	for _, attr := range resource.GetAttributes() {
+		if attr.GetKey() == "service.name" {
+			return attr.GetValue().GetStringValue()
+		}
+	}
+	return "unknown_service"
+}
+
+func attributesToMap(attrs []*commonpb.KeyValue) map[string]interface{} {
+	out := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		out[attr.GetKey()] = anyValueToInterface(attr.GetValue())
+	}
+	return out
+}
+
+func anyValueToInterface(v *commonpb.AnyValue) interface{} {
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return val.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return val.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return val.DoubleValue
+	case *commonpb.AnyValue_ArrayValue:
+		items := val.ArrayValue.GetValues()
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = anyValueToInterface(item)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// formatUnixNano renders OTLP's fixed64 unix-nano timestamps as RFC3339
+// strings so they line up with the start_time format the rest of the
+// store already expects.
+func formatUnixNano(nanos uint64) string {
+	if nanos == 0 {
+		return ""
+	}
+	return time.Unix(0, int64(nanos)).UTC().Format(time.RFC3339Nano)
+}
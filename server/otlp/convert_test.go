@@ -0,0 +1,124 @@
+package otlp
+
+import (
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func TestConvertResourceSpans_GroupsByTraceID(t *testing.T) {
+	rs := &tracepb.ResourceSpans{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", "checkout")}},
+		ScopeSpans: []*tracepb.ScopeSpans{
+			{
+				Spans: []*tracepb.Span{
+					{TraceId: []byte{1}, SpanId: []byte{1}, Name: "root"},
+					{TraceId: []byte{1}, SpanId: []byte{2}, ParentSpanId: []byte{1}, Name: "child"},
+					{TraceId: []byte{2}, SpanId: []byte{3}, Name: "other-trace"},
+				},
+			},
+		},
+	}
+
+	traces := ConvertResourceSpans(rs)
+
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces, got %d", len(traces))
+	}
+
+	first, ok := traces["01"]
+	if !ok {
+		t.Fatalf("expected a trace keyed by trace_id %q", "01")
+	}
+	if first["service"] != "checkout" {
+		t.Errorf("expected service %q, got %v", "checkout", first["service"])
+	}
+	spans, ok := first["spans"].([]map[string]interface{})
+	if !ok || len(spans) != 2 {
+		t.Fatalf("expected 2 spans on trace 01, got %v", first["spans"])
+	}
+}
+
+func TestConvertResourceSpans_SkipsSpansWithoutTraceID(t *testing.T) {
+	rs := &tracepb.ResourceSpans{
+		ScopeSpans: []*tracepb.ScopeSpans{
+			{Spans: []*tracepb.Span{{Name: "no-trace-id"}}},
+		},
+	}
+
+	traces := ConvertResourceSpans(rs)
+
+	if len(traces) != 0 {
+		t.Errorf("expected no traces for a span without a trace ID, got %d", len(traces))
+	}
+}
+
+func TestConvertResourceSpans_ErrorStatusPropagatesToTrace(t *testing.T) {
+	rs := &tracepb.ResourceSpans{
+		ScopeSpans: []*tracepb.ScopeSpans{
+			{
+				Spans: []*tracepb.Span{
+					{TraceId: []byte{1}, SpanId: []byte{1}, Name: "ok-span"},
+					{
+						TraceId: []byte{1},
+						SpanId:  []byte{2},
+						Name:    "failing-span",
+						Status:  &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR},
+					},
+				},
+			},
+		},
+	}
+
+	traces := ConvertResourceSpans(rs)
+
+	if traces["01"]["status"] != "error" {
+		t.Errorf("expected trace status %q once any span errors, got %v", "error", traces["01"]["status"])
+	}
+}
+
+func TestServiceNameFromResource_DefaultsWhenMissing(t *testing.T) {
+	if got := serviceNameFromResource(&resourcepb.Resource{}); got != "unknown_service" {
+		t.Errorf("expected default service name %q, got %q", "unknown_service", got)
+	}
+}
+
+func TestAnyValueToInterface(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *commonpb.AnyValue
+		want interface{}
+	}{
+		{"string", &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "x"}}, "x"},
+		{"bool", &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}, true},
+		{"int", &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 42}}, int64(42)},
+		{"double", &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: 1.5}}, 1.5},
+		{"unset", &commonpb.AnyValue{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := anyValueToInterface(tt.in); got != tt.want {
+				t.Errorf("anyValueToInterface(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatUnixNano(t *testing.T) {
+	if got := formatUnixNano(0); got != "" {
+		t.Errorf("expected empty string for zero timestamp, got %q", got)
+	}
+	if got := formatUnixNano(1700000000000000000); got == "" {
+		t.Errorf("expected a non-empty RFC3339 timestamp for a nonzero input")
+	}
+}
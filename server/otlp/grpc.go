@@ -0,0 +1,42 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/traceloop-ai/traceloop/server/sampling"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+)
+
+// TraceServiceServer implements the OTLP collector TraceService, running
+// every incoming trace through sampler before persisting it through
+// writer.
+type TraceServiceServer struct {
+	collectortracepb.UnimplementedTraceServiceServer
+
+	writer  Writer
+	sampler *sampling.Sampler
+}
+
+// NewTraceServiceServer creates an OTLP TraceService backed by writer,
+// applying sampler's sampling policies to every trace before it is
+// persisted.
+func NewTraceServiceServer(writer Writer, sampler *sampling.Sampler) *TraceServiceServer {
+	return &TraceServiceServer{writer: writer, sampler: sampler}
+}
+
+// Export implements opentelemetry.proto.collector.trace.v1.TraceService/Export.
+func (s *TraceServiceServer) Export(ctx context.Context, req *collectortracepb.ExportTraceServiceRequest) (*collectortracepb.ExportTraceServiceResponse, error) {
+	for _, rs := range req.GetResourceSpans() {
+		for traceID, trace := range ConvertResourceSpans(rs) {
+			err := s.sampler.Process(ctx, trace, func(ctx context.Context, trace map[string]interface{}) error {
+				return s.writer.StoreTrace(ctx, trace)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to store trace %s: %w", traceID, err)
+			}
+		}
+	}
+
+	return &collectortracepb.ExportTraceServiceResponse{}, nil
+}
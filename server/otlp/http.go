@@ -0,0 +1,73 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/traceloop-ai/traceloop/server/sampling"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTPHandler serves the OTLP/HTTP trace ingest endpoint, accepting both
+// Protobuf (application/x-protobuf) and JSON (application/json) request
+// bodies as defined by the OTLP/HTTP spec.
+type HTTPHandler struct {
+	writer  Writer
+	sampler *sampling.Sampler
+}
+
+// NewHTTPHandler creates an OTLP/HTTP handler backed by writer, applying
+// sampler's sampling policies to every trace before it is persisted.
+func NewHTTPHandler(writer Writer, sampler *sampling.Sampler) *HTTPHandler {
+	return &HTTPHandler{writer: writer, sampler: sampler}
+}
+
+// ServeTraces handles POST requests to /v1/traces.
+func (h *HTTPHandler) ServeTraces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req := &collectortracepb.ExportTraceServiceRequest{}
+	if isJSON(r.Header.Get("Content-Type")) {
+		err = protojson.Unmarshal(body, req)
+	} else {
+		err = proto.Unmarshal(body, req)
+	}
+	if err != nil {
+		http.Error(w, "failed to parse OTLP export request", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	for _, rs := range req.GetResourceSpans() {
+		for _, trace := range ConvertResourceSpans(rs) {
+			err := h.sampler.Process(ctx, trace, func(ctx context.Context, trace map[string]interface{}) error {
+				return h.writer.StoreTrace(ctx, trace)
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"partialSuccess":{}}`))
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json"
+}
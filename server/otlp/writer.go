@@ -0,0 +1,11 @@
+package otlp
+
+import "context"
+
+// Writer is the write path used to persist a converted trace. It is
+// satisfied directly by storage.Store, and by server.Server when
+// clustered mode is enabled so writes go through Raft consensus and
+// leader-forwarding instead of the local store.
+type Writer interface {
+	StoreTrace(ctx context.Context, trace map[string]interface{}) error
+}
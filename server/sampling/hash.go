@@ -0,0 +1,24 @@
+package sampling
+
+import "hash/fnv"
+
+// headSampleDecision deterministically decides whether traceID should be
+// sampled at rate (0-1), by hashing it to a uniformly distributed bucket
+// mod 10000. The same trace_id always yields the same decision, so every
+// span belonging to one trace is sampled together regardless of which
+// ingest call it arrives on.
+func headSampleDecision(traceID string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+
+	const buckets = 10000
+	h := fnv.New32a()
+	h.Write([]byte(traceID))
+	bucket := h.Sum32() % buckets
+
+	return bucket < uint32(rate*buckets)
+}
@@ -0,0 +1,54 @@
+package sampling
+
+import "testing"
+
+func TestHeadSampleDecision_BoundaryRates(t *testing.T) {
+	if headSampleDecision("trace-1", 0) {
+		t.Error("expected rate 0 to never sample")
+	}
+	if !headSampleDecision("trace-1", 1) {
+		t.Error("expected rate 1 to always sample")
+	}
+}
+
+func TestHeadSampleDecision_DeterministicPerTraceID(t *testing.T) {
+	for _, traceID := range []string{"trace-a", "trace-b", "trace-c"} {
+		first := headSampleDecision(traceID, 0.5)
+		for i := 0; i < 10; i++ {
+			if got := headSampleDecision(traceID, 0.5); got != first {
+				t.Fatalf("headSampleDecision(%q, 0.5) is not deterministic: got %v, want %v", traceID, got, first)
+			}
+		}
+	}
+}
+
+func TestHeadSampleDecision_ApproximatesRate(t *testing.T) {
+	const rate = 0.25
+	const n = 20000
+
+	sampled := 0
+	for i := 0; i < n; i++ {
+		traceID := randomishTraceID(i)
+		if headSampleDecision(traceID, rate) {
+			sampled++
+		}
+	}
+
+	got := float64(sampled) / n
+	if got < rate-0.05 || got > rate+0.05 {
+		t.Errorf("sampled fraction %.3f too far from target rate %.3f", got, rate)
+	}
+}
+
+// randomishTraceID deterministically derives distinct trace IDs without
+// relying on math/rand, since test determinism matters more than true
+// randomness here.
+func randomishTraceID(i int) string {
+	const alphabet = "0123456789abcdef"
+	b := make([]byte, 16)
+	for j := range b {
+		i = i*1103515245 + 12345
+		b[j] = alphabet[(i>>16)&0xf]
+	}
+	return string(b)
+}
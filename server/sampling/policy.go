@@ -0,0 +1,85 @@
+package sampling
+
+import (
+	"fmt"
+	"time"
+)
+
+// rootSpanDuration returns the duration of trace's root span (the span
+// with no parent_span_id), or 0 if it can't be determined.
+func rootSpanDuration(trace map[string]interface{}) time.Duration {
+	spans, _ := trace["spans"].([]map[string]interface{})
+	for _, span := range spans {
+		if parent, _ := span["parent_span_id"].(string); parent != "" {
+			continue
+		}
+
+		start, ok := parseSpanTime(span["start_time"])
+		if !ok {
+			continue
+		}
+		end, ok := parseSpanTime(span["end_time"])
+		if !ok {
+			continue
+		}
+		return end.Sub(start)
+	}
+	return 0
+}
+
+func parseSpanTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// attributesMatch reports whether every key/value in want is present in
+// trace's (or any of its spans') attributes.
+func attributesMatch(trace map[string]interface{}, want map[string]string) bool {
+	for key, value := range want {
+		if !attributeEquals(trace["attributes"], key, value) {
+			if !spanAttributeEquals(trace, key, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attributeEquals(attrs interface{}, key, want string) bool {
+	m, ok := attrs.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	got, ok := m[key]
+	return ok && fmt.Sprintf("%v", got) == want
+}
+
+func spanAttributeEquals(trace map[string]interface{}, key, want string) bool {
+	spans, _ := trace["spans"].([]map[string]interface{})
+	for _, span := range spans {
+		if attributeEquals(span["attributes"], key, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSpans appends incoming's spans to existing, so that spans for the
+// same trace arriving across multiple ingest calls accumulate in the
+// tail-sampling buffer instead of overwriting each other.
+func mergeSpans(existing, incoming map[string]interface{}) {
+	existingSpans, _ := existing["spans"].([]map[string]interface{})
+	incomingSpans, _ := incoming["spans"].([]map[string]interface{})
+	existing["spans"] = append(existingSpans, incomingSpans...)
+
+	if status, _ := incoming["status"].(string); status == "error" {
+		existing["status"] = "error"
+	}
+}
@@ -0,0 +1,106 @@
+package sampling
+
+import "testing"
+
+func TestRootSpanDuration(t *testing.T) {
+	trace := map[string]interface{}{
+		"spans": []map[string]interface{}{
+			{
+				"parent_span_id": "",
+				"start_time":     "2024-01-01T00:00:00Z",
+				"end_time":       "2024-01-01T00:00:02Z",
+			},
+			{
+				"parent_span_id": "root",
+				"start_time":     "2024-01-01T00:00:00Z",
+				"end_time":       "2024-01-01T00:10:00Z",
+			},
+		},
+	}
+
+	got := rootSpanDuration(trace)
+	if got.Seconds() != 2 {
+		t.Errorf("expected root span duration of 2s, got %v", got)
+	}
+}
+
+func TestRootSpanDuration_NoRootSpan(t *testing.T) {
+	trace := map[string]interface{}{
+		"spans": []map[string]interface{}{
+			{"parent_span_id": "root", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T00:00:01Z"},
+		},
+	}
+
+	if got := rootSpanDuration(trace); got != 0 {
+		t.Errorf("expected 0 duration when no root span is present, got %v", got)
+	}
+}
+
+func TestAttributesMatch_TraceLevel(t *testing.T) {
+	trace := map[string]interface{}{
+		"attributes": map[string]interface{}{"llm.model": "gpt-4"},
+	}
+
+	if !attributesMatch(trace, map[string]string{"llm.model": "gpt-4"}) {
+		t.Error("expected trace-level attribute match")
+	}
+	if attributesMatch(trace, map[string]string{"llm.model": "gpt-3"}) {
+		t.Error("expected no match for a different attribute value")
+	}
+}
+
+func TestAttributesMatch_FallsBackToSpanAttributes(t *testing.T) {
+	trace := map[string]interface{}{
+		"attributes": map[string]interface{}{},
+		"spans": []map[string]interface{}{
+			{"attributes": map[string]interface{}{"llm.model": "gpt-4"}},
+		},
+	}
+
+	if !attributesMatch(trace, map[string]string{"llm.model": "gpt-4"}) {
+		t.Error("expected a span-level attribute to satisfy the match")
+	}
+}
+
+func TestAttributesMatch_RequiresEveryKey(t *testing.T) {
+	trace := map[string]interface{}{
+		"attributes": map[string]interface{}{"llm.model": "gpt-4"},
+	}
+
+	want := map[string]string{"llm.model": "gpt-4", "llm.provider": "openai"}
+	if attributesMatch(trace, want) {
+		t.Error("expected no match when one of several required attributes is missing")
+	}
+}
+
+func TestMergeSpans(t *testing.T) {
+	existing := map[string]interface{}{
+		"status": "ok",
+		"spans":  []map[string]interface{}{{"span_id": "1"}},
+	}
+	incoming := map[string]interface{}{
+		"status": "error",
+		"spans":  []map[string]interface{}{{"span_id": "2"}},
+	}
+
+	mergeSpans(existing, incoming)
+
+	spans := existing["spans"].([]map[string]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 merged spans, got %d", len(spans))
+	}
+	if existing["status"] != "error" {
+		t.Errorf("expected an incoming error status to propagate, got %v", existing["status"])
+	}
+}
+
+func TestMergeSpans_KeepsOkStatusWhenIncomingIsOk(t *testing.T) {
+	existing := map[string]interface{}{"status": "ok", "spans": []map[string]interface{}{}}
+	incoming := map[string]interface{}{"status": "ok", "spans": []map[string]interface{}{}}
+
+	mergeSpans(existing, incoming)
+
+	if existing["status"] != "ok" {
+		t.Errorf("expected status to remain ok, got %v", existing["status"])
+	}
+}
@@ -0,0 +1,44 @@
+package sampling
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a fixed-window per-second counter keyed by service name,
+// used by Policy.RateLimitPerSecond to cap how many traces its rule keeps
+// for a given service.
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	second int64
+	count  int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[string]*window)}
+}
+
+// allow reports whether one more trace may be kept for service this
+// second under a limit of perSecond.
+func (r *rateLimiter) allow(service string, perSecond int) bool {
+	now := time.Now().Unix()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.windows[service]
+	if !ok || w.second != now {
+		w = &window{second: now}
+		r.windows[service] = w
+	}
+
+	if w.count >= perSecond {
+		return false
+	}
+	w.count++
+	return true
+}
@@ -0,0 +1,128 @@
+// Package sampling implements head- and tail-based sampling of traces
+// before they reach storage.Store, so that AI-agent workloads with
+// token-heavy traces don't have to pay to store every one of them.
+package sampling
+
+import (
+	"context"
+	"time"
+)
+
+// Config configures the sampler. It is meant to be populated from the
+// "sampling" block of the server's YAML config file.
+type Config struct {
+	Enabled bool    `yaml:"enabled"`
+	Rate    float64 `yaml:"rate"`
+
+	// ServiceOverrides overrides Rate for specific services.
+	ServiceOverrides map[string]float64 `yaml:"service_overrides"`
+
+	Tail TailConfig `yaml:"tail"`
+}
+
+// TailConfig configures tail-based sampling, which buffers a trace's
+// spans for DecisionWindow after its first span arrives, then evaluates
+// Policies to decide whether to keep or drop it.
+type TailConfig struct {
+	Enabled           bool          `yaml:"enabled"`
+	DecisionWindow    time.Duration `yaml:"decision_window"`
+	MaxBufferedTraces int           `yaml:"max_buffered_traces"`
+	Policies          []Policy      `yaml:"policies"`
+}
+
+// Policy is one tail-sampling rule. A trace is kept if it matches any
+// configured policy. Each field is independently optional; a zero value
+// means that rule is not checked.
+type Policy struct {
+	// AlwaysSampleErrors keeps any trace whose status is "error".
+	AlwaysSampleErrors bool `yaml:"always_sample_errors"`
+	// MinRootDuration keeps traces whose root span took longer than this.
+	MinRootDuration time.Duration `yaml:"latency_threshold"`
+	// AttributeEquals keeps traces where every listed attribute matches
+	// (e.g. llm.model == "gpt-4").
+	AttributeEquals map[string]string `yaml:"attribute_equals"`
+	// RateLimitPerSecond keeps up to this many traces per second for a
+	// single service, whatever their content; traces kept by another
+	// rule don't count towards it. The per-service count is shared by
+	// every policy that sets this field.
+	RateLimitPerSecond int `yaml:"rate_limit_per_service"`
+}
+
+// DefaultMaxBufferedTraces bounds the tail sampler's in-flight trace
+// buffer when a config doesn't set one explicitly.
+const DefaultMaxBufferedTraces = 10000
+
+// Sampler applies Config's head- and tail-based rules to traces flowing
+// through the OTLP ingest paths.
+type Sampler struct {
+	cfg   Config
+	tail  *tailBuffer
+	Stats *Stats
+}
+
+// New creates a Sampler from cfg. When cfg.Enabled is false, every trace
+// passed to Process is flushed unconditionally.
+func New(cfg Config) *Sampler {
+	s := &Sampler{cfg: cfg, Stats: &Stats{}}
+
+	if cfg.Enabled && cfg.Tail.Enabled {
+		maxBuffered := cfg.Tail.MaxBufferedTraces
+		if maxBuffered <= 0 {
+			maxBuffered = DefaultMaxBufferedTraces
+		}
+		window := cfg.Tail.DecisionWindow
+		if window <= 0 {
+			window = 10 * time.Second
+		}
+		s.tail = newTailBuffer(maxBuffered, window, cfg.Tail.Policies, s.Stats)
+	}
+
+	return s
+}
+
+// Flush persists a trace, e.g. storage.Store.StoreTrace. Tail-sampled
+// traces are flushed asynchronously, well after the ingest call that
+// produced them has returned, so flush must not assume ctx is the
+// original request's context — callers get context.Background() in
+// that case; see tailBuffer.decideAndFlush.
+type Flush func(ctx context.Context, trace map[string]interface{}) error
+
+// Process decides whether trace should be kept, buffering it for
+// tail-based evaluation if configured, and calls flush for every trace
+// that is ultimately kept. Process itself never blocks on the decision
+// window; tail-sampled traces are flushed asynchronously once their
+// window elapses, using a background context rather than ctx, since
+// ctx is scoped to the ingest call and will already be canceled by then.
+func (s *Sampler) Process(ctx context.Context, trace map[string]interface{}, flush Flush) error {
+	if !s.cfg.Enabled {
+		return flush(ctx, trace)
+	}
+
+	if s.tail != nil {
+		s.tail.Add(trace, flush)
+		return nil
+	}
+
+	if s.headSample(trace) {
+		s.Stats.AddSampled(1)
+		return flush(ctx, trace)
+	}
+
+	s.Stats.AddDropped(1)
+	return nil
+}
+
+// headSample makes a deterministic decision based on a hash of the
+// trace's trace_id, so that every span belonging to the same trace is
+// sampled consistently.
+func (s *Sampler) headSample(trace map[string]interface{}) bool {
+	rate := s.cfg.Rate
+	if service, ok := trace["service"].(string); ok {
+		if override, ok := s.cfg.ServiceOverrides[service]; ok {
+			rate = override
+		}
+	}
+
+	traceID, _ := trace["trace_id"].(string)
+	return headSampleDecision(traceID, rate)
+}
@@ -0,0 +1,47 @@
+package sampling
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSampler_TailFlush_NotBoundToIngestContext guards against a
+// regression where the deferred tail-sampling flush reused the
+// request-scoped context it was given during Process, which is already
+// canceled by the time the decision window elapses and the trace is
+// actually flushed.
+func TestSampler_TailFlush_NotBoundToIngestContext(t *testing.T) {
+	s := New(Config{
+		Enabled: true,
+		Tail: TailConfig{
+			Enabled:        true,
+			DecisionWindow: time.Hour, // long enough that evaluate() below runs before the real timer would
+			Policies:       []Policy{{AlwaysSampleErrors: true}},
+		},
+	})
+
+	ingestCtx, cancel := context.WithCancel(context.Background())
+	cancel() // simulate the ingest call having already returned
+
+	var flushCtx context.Context
+	trace := map[string]interface{}{"trace_id": "t1", "status": "error"}
+
+	if err := s.Process(ingestCtx, trace, func(ctx context.Context, trace map[string]interface{}) error {
+		flushCtx = ctx
+		return nil
+	}); err != nil {
+		t.Fatalf("Process returned an error: %v", err)
+	}
+
+	// Trigger the tail-sampling decision synchronously instead of
+	// waiting for the real timer, simulating the window elapsing.
+	s.tail.evaluate("t1")
+
+	if flushCtx == nil {
+		t.Fatal("expected flush to have been called")
+	}
+	if err := flushCtx.Err(); err != nil {
+		t.Errorf("expected the deferred flush to get a live context, got one that is already %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package sampling
+
+import "sync/atomic"
+
+// Stats holds the running sampled/dropped counters exposed through the
+// server's /api/v1/stats endpoint.
+type Stats struct {
+	sampled uint64
+	dropped uint64
+}
+
+// AddSampled increments the sampled counter by n.
+func (s *Stats) AddSampled(n uint64) { atomic.AddUint64(&s.sampled, n) }
+
+// AddDropped increments the dropped counter by n.
+func (s *Stats) AddDropped(n uint64) { atomic.AddUint64(&s.dropped, n) }
+
+// Snapshot returns the current sampled/dropped totals.
+func (s *Stats) Snapshot() (sampled, dropped uint64) {
+	return atomic.LoadUint64(&s.sampled), atomic.LoadUint64(&s.dropped)
+}
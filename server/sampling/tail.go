@@ -0,0 +1,166 @@
+package sampling
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// bufferedTrace is one trace's accumulated spans awaiting a tail-sampling
+// decision.
+type bufferedTrace struct {
+	traceID string
+	trace   map[string]interface{}
+	flush   Flush
+	timer   *time.Timer
+}
+
+// tailBuffer buffers spans per trace_id for a decision window, then
+// evaluates policies to decide whether to flush or drop the trace. It
+// bounds its own memory with an LRU eviction policy: once maxSize traces
+// are buffered, the least-recently-touched trace is evicted (and
+// dropped) to make room.
+type tailBuffer struct {
+	mu       sync.Mutex
+	window   time.Duration
+	maxSize  int
+	policies []Policy
+	stats    *Stats
+	limiter  *rateLimiter
+
+	order   *list.List               // front = most recently touched
+	entries map[string]*list.Element // trace_id -> element wrapping *bufferedTrace
+}
+
+func newTailBuffer(maxSize int, window time.Duration, policies []Policy, stats *Stats) *tailBuffer {
+	return &tailBuffer{
+		window:   window,
+		maxSize:  maxSize,
+		policies: policies,
+		stats:    stats,
+		limiter:  newRateLimiter(),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Add buffers trace under its trace_id, merging it with any spans
+// already buffered for that trace, and (for new traces) schedules the
+// sampling decision after the decision window.
+func (b *tailBuffer) Add(trace map[string]interface{}, flush Flush) {
+	traceID, _ := trace["trace_id"].(string)
+	if traceID == "" {
+		b.decideAndFlush(trace, flush)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if el, ok := b.entries[traceID]; ok {
+		bt := el.Value.(*bufferedTrace)
+		mergeSpans(bt.trace, trace)
+		b.order.MoveToFront(el)
+		return
+	}
+
+	bt := &bufferedTrace{traceID: traceID, trace: trace, flush: flush}
+	el := b.order.PushFront(bt)
+	b.entries[traceID] = el
+	bt.timer = time.AfterFunc(b.window, func() { b.evaluate(traceID) })
+
+	b.evictOldest()
+}
+
+// evictOldest drops the least-recently-touched buffered trace(s) once
+// the buffer exceeds maxSize, so a flood of distinct trace IDs can't
+// grow the buffer without bound.
+func (b *tailBuffer) evictOldest() {
+	for len(b.entries) > b.maxSize {
+		oldest := b.order.Back()
+		if oldest == nil {
+			return
+		}
+		bt := oldest.Value.(*bufferedTrace)
+		bt.timer.Stop()
+		b.order.Remove(oldest)
+		delete(b.entries, bt.traceID)
+		b.stats.AddDropped(1)
+	}
+}
+
+func (b *tailBuffer) evaluate(traceID string) {
+	b.mu.Lock()
+	el, ok := b.entries[traceID]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	bt := el.Value.(*bufferedTrace)
+	b.order.Remove(el)
+	delete(b.entries, traceID)
+	b.mu.Unlock()
+
+	b.decideAndFlush(bt.trace, bt.flush)
+}
+
+func (b *tailBuffer) decideAndFlush(trace map[string]interface{}, flush Flush) {
+	if !b.matchesAnyPolicy(trace) {
+		b.stats.AddDropped(1)
+		return
+	}
+
+	// A buffered trace is flushed well after the ingest call that
+	// produced it has returned, so its context would already be
+	// canceled; use a fresh background context instead.
+	if err := flush(context.Background(), trace); err != nil {
+		// A buffered trace is flushed after Process already returned, so
+		// there's no one left to report the error to; count it the same
+		// as a drop. A trace without a trace_id is flushed synchronously
+		// from Add, and its error is counted the same way.
+		b.stats.AddDropped(1)
+		return
+	}
+	b.stats.AddSampled(1)
+}
+
+func (b *tailBuffer) matchesAnyPolicy(trace map[string]interface{}) bool {
+	if len(b.policies) == 0 {
+		// No policies configured: the decision window is purely a
+		// buffering delay, not a filter.
+		return true
+	}
+
+	for _, policy := range b.policies {
+		if b.matchesPolicy(trace, policy) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *tailBuffer) matchesPolicy(trace map[string]interface{}, policy Policy) bool {
+	if policy.AlwaysSampleErrors {
+		if status, _ := trace["status"].(string); status == "error" {
+			return true
+		}
+	}
+
+	if policy.MinRootDuration > 0 && rootSpanDuration(trace) > policy.MinRootDuration {
+		return true
+	}
+
+	if len(policy.AttributeEquals) > 0 && attributesMatch(trace, policy.AttributeEquals) {
+		return true
+	}
+
+	if policy.RateLimitPerSecond > 0 {
+		service, _ := trace["service"].(string)
+		if b.limiter.allow(service, policy.RateLimitPerSecond) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestBuffer(maxSize int, policies []Policy) *tailBuffer {
+	return newTailBuffer(maxSize, time.Hour, policies, &Stats{})
+}
+
+func TestTailBuffer_Add_MergesSpansForSameTrace(t *testing.T) {
+	b := newTestBuffer(10, nil)
+
+	b.Add(map[string]interface{}{
+		"trace_id": "t1",
+		"status":   "ok",
+		"spans":    []map[string]interface{}{{"span_id": "1"}},
+	}, func(context.Context, map[string]interface{}) error { return nil })
+
+	b.Add(map[string]interface{}{
+		"trace_id": "t1",
+		"status":   "ok",
+		"spans":    []map[string]interface{}{{"span_id": "2"}},
+	}, func(context.Context, map[string]interface{}) error { return nil })
+
+	if len(b.entries) != 1 {
+		t.Fatalf("expected 1 buffered trace after merging, got %d", len(b.entries))
+	}
+
+	bt := b.entries["t1"].Value.(*bufferedTrace)
+	spans := bt.trace["spans"].([]map[string]interface{})
+	if len(spans) != 2 {
+		t.Errorf("expected merged trace to have 2 spans, got %d", len(spans))
+	}
+}
+
+func TestTailBuffer_EvictsOldestWhenOverCapacity(t *testing.T) {
+	b := newTestBuffer(2, nil)
+
+	for i := 0; i < 3; i++ {
+		traceID := fmt.Sprintf("t%d", i)
+		b.Add(map[string]interface{}{"trace_id": traceID}, func(context.Context, map[string]interface{}) error { return nil })
+	}
+
+	if len(b.entries) != 2 {
+		t.Fatalf("expected buffer capped at 2 entries, got %d", len(b.entries))
+	}
+	if _, ok := b.entries["t0"]; ok {
+		t.Error("expected the oldest trace (t0) to be evicted")
+	}
+
+	sampled, dropped := b.stats.Snapshot()
+	if sampled != 0 || dropped != 1 {
+		t.Errorf("expected 1 dropped trace from eviction, got sampled=%d dropped=%d", sampled, dropped)
+	}
+}
+
+func TestTailBuffer_MoveToFrontProtectsRecentlyTouchedTrace(t *testing.T) {
+	b := newTestBuffer(2, nil)
+
+	b.Add(map[string]interface{}{"trace_id": "t0"}, func(context.Context, map[string]interface{}) error { return nil })
+	b.Add(map[string]interface{}{"trace_id": "t1"}, func(context.Context, map[string]interface{}) error { return nil })
+
+	// Touching t0 again should move it to the front, so the next
+	// insertion evicts t1 instead.
+	b.Add(map[string]interface{}{"trace_id": "t0"}, func(context.Context, map[string]interface{}) error { return nil })
+	b.Add(map[string]interface{}{"trace_id": "t2"}, func(context.Context, map[string]interface{}) error { return nil })
+
+	if _, ok := b.entries["t0"]; !ok {
+		t.Error("expected recently-touched t0 to survive eviction")
+	}
+	if _, ok := b.entries["t1"]; ok {
+		t.Error("expected least-recently-touched t1 to be evicted")
+	}
+}
+
+func TestTailBuffer_MatchesAnyPolicy_NoPoliciesKeepsEverything(t *testing.T) {
+	b := newTestBuffer(10, nil)
+	if !b.matchesAnyPolicy(map[string]interface{}{}) {
+		t.Error("expected an empty policy set to keep every trace")
+	}
+}
+
+func TestTailBuffer_MatchesPolicy_AlwaysSampleErrors(t *testing.T) {
+	b := newTestBuffer(10, []Policy{{AlwaysSampleErrors: true}})
+
+	errored := map[string]interface{}{"status": "error"}
+	ok := map[string]interface{}{"status": "ok"}
+
+	if !b.matchesAnyPolicy(errored) {
+		t.Error("expected an errored trace to match AlwaysSampleErrors")
+	}
+	if b.matchesAnyPolicy(ok) {
+		t.Error("expected a healthy trace not to match AlwaysSampleErrors")
+	}
+}
+
+func TestTailBuffer_MatchesPolicy_MinRootDuration(t *testing.T) {
+	b := newTestBuffer(10, []Policy{{MinRootDuration: time.Second}})
+
+	slow := map[string]interface{}{
+		"spans": []map[string]interface{}{
+			{"parent_span_id": "", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T00:00:05Z"},
+		},
+	}
+	fast := map[string]interface{}{
+		"spans": []map[string]interface{}{
+			{"parent_span_id": "", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T00:00:00.1Z"},
+		},
+	}
+
+	if !b.matchesAnyPolicy(slow) {
+		t.Error("expected a slow root span to exceed MinRootDuration")
+	}
+	if b.matchesAnyPolicy(fast) {
+		t.Error("expected a fast root span not to exceed MinRootDuration")
+	}
+}
+
+func TestTailBuffer_DecideAndFlush_DropsWhenNoPolicyMatches(t *testing.T) {
+	b := newTestBuffer(10, []Policy{{AlwaysSampleErrors: true}})
+
+	flushed := false
+	b.decideAndFlush(map[string]interface{}{"status": "ok"}, func(context.Context, map[string]interface{}) error {
+		flushed = true
+		return nil
+	})
+
+	if flushed {
+		t.Error("expected decideAndFlush not to flush a trace matching no policy")
+	}
+	_, dropped := b.stats.Snapshot()
+	if dropped != 1 {
+		t.Errorf("expected 1 dropped trace, got %d", dropped)
+	}
+}
+
+func TestTailBuffer_DecideAndFlush_FlushesOnMatch(t *testing.T) {
+	b := newTestBuffer(10, []Policy{{AlwaysSampleErrors: true}})
+
+	flushed := false
+	b.decideAndFlush(map[string]interface{}{"status": "error"}, func(context.Context, map[string]interface{}) error {
+		flushed = true
+		return nil
+	})
+
+	if !flushed {
+		t.Error("expected decideAndFlush to flush a trace matching a policy")
+	}
+	sampled, _ := b.stats.Snapshot()
+	if sampled != 1 {
+		t.Errorf("expected 1 sampled trace, got %d", sampled)
+	}
+}
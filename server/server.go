@@ -1,49 +1,129 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"github.com/traceloop-ai/traceloop/server/cluster"
+	"github.com/traceloop-ai/traceloop/server/config"
 	"github.com/traceloop-ai/traceloop/server/grpc"
+	"github.com/traceloop-ai/traceloop/server/otlp"
+	"github.com/traceloop-ai/traceloop/server/sampling"
 	"github.com/traceloop-ai/traceloop/server/storage"
+	"github.com/traceloop-ai/traceloop/server/telemetry"
 )
 
 // Config holds the server configuration
 type Config struct {
 	Host string
 	Port int
+
+	// OTLPGRPCPort is the port the OTLP gRPC TraceService listens on.
+	OTLPGRPCPort int
+	// OTLPHTTPPort is the port the OTLP/HTTP trace receiver listens on.
+	OTLPHTTPPort int
+
+	// Storage selects the storage driver to use (e.g. "badger",
+	// "elasticsearch", "clickhouse"). See the storage package for the
+	// registered drivers.
+	Storage string
+	// StorageDSN is passed through to the selected storage driver: a
+	// filesystem path for badger, a comma-separated address list for
+	// elasticsearch, or a connection string for clickhouse.
+	StorageDSN string
+
+	// ConfigFile is the path to an optional YAML config file, currently
+	// used to configure the sampling subsystem and self-instrumentation.
+	ConfigFile string
+
+	// RaftDir enables clustered mode, holding this node's Raft log,
+	// stable store and snapshots. Empty disables clustering entirely.
+	RaftDir string
+	// RaftPort is the port this node's Raft transport binds to.
+	RaftPort int
+	// ClusterPeers lists the raft addresses of an existing cluster to
+	// join. Empty bootstraps a brand new single-node cluster. A non-empty
+	// list only keeps this node from bootstrapping; the addresses are not
+	// contacted, and the node joins once the /cluster/join admin endpoint
+	// is called against the existing cluster's leader.
+	ClusterPeers []string
 }
 
 // Server represents the main traceloop server
 type Server struct {
-	config  Config
-	storage storage.Store
-	grpc    *grpc.Server
-	http    *http.Server
+	config    Config
+	storage   storage.Store
+	sampler   *sampling.Sampler
+	cluster   *cluster.Cluster
+	telemetry *telemetry.Provider
+	grpc      *grpc.Server
+	http      *http.Server
+	otlpHTTP  *http.Server
 }
 
 // Start initializes and starts the traceloop server
-func Start(config Config) error {
+func Start(cfg Config) error {
+	fileConfig, err := config.Load(cfg.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+	sampler := sampling.New(fileConfig.Sampling)
+
+	telemetryProvider, err := telemetry.New(fileConfig.Trace)
+	if err != nil {
+		return fmt.Errorf("failed to initialize telemetry: %w", err)
+	}
+
 	// Initialize storage
-	store, err := storage.NewBadgerStore("./data")
+	store, err := storage.New(cfg.Storage, cfg.StorageDSN)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
 	server := &Server{
-		config:  config,
-		storage: store,
+		config:    cfg,
+		storage:   storage.Instrumented(store, cfg.Storage),
+		sampler:   sampler,
+		telemetry: telemetryProvider,
 	}
 
-	// Start gRPC server for SDK communication
-	grpcServer, err := grpc.NewServer(store)
+	if cfg.RaftDir != "" {
+		badgerStore, ok := store.(*storage.BadgerStore)
+		if !ok {
+			return fmt.Errorf("clustered mode requires the badger storage driver")
+		}
+
+		raftAddr := fmt.Sprintf("%s:%d", cfg.Host, cfg.RaftPort)
+		c, err := cluster.New(cluster.Config{
+			NodeID:    raftAddr,
+			RaftAddr:  raftAddr,
+			RaftDir:   cfg.RaftDir,
+			APIAddr:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Bootstrap: len(cfg.ClusterPeers) == 0,
+		}, badgerStore)
+		if err != nil {
+			return fmt.Errorf("failed to start raft node: %w", err)
+		}
+		server.cluster = c
+	}
+
+	// Start gRPC server for SDK communication. server itself is passed as
+	// the OTLP write path, so clustered writes go through Raft consensus
+	// and leader-forwarding (see Server.StoreTrace).
+	grpcServer, err := grpc.NewServer(server.storage, server, sampler)
 	if err != nil {
 		return fmt.Errorf("failed to create gRPC server: %w", err)
 	}
@@ -51,18 +131,29 @@ func Start(config Config) error {
 
 	// Start HTTP server for REST API and web UI
 	router := gin.Default()
+	router.Use(otelgin.Middleware(telemetry.ServiceName))
 	server.setupRoutes(router)
 
 	httpServer := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
+		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Handler: router,
 	}
 	server.http = httpServer
 
+	// OTLP/HTTP trace receiver, so any OpenTelemetry SDK can push traces
+	// directly into traceloop without a custom SDK.
+	otlpMux := http.NewServeMux()
+	otlpMux.HandleFunc("/v1/traces", otlp.NewHTTPHandler(server, sampler).ServeTraces)
+	otlpHTTPServer := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.OTLPHTTPPort),
+		Handler: otlpMux,
+	}
+	server.otlpHTTP = otlpHTTPServer
+
 	// Start servers in goroutines
 	go func() {
-		log.Printf("Starting gRPC server on port %d", config.Port+1)
-		if err := server.grpc.Start(config.Port + 1); err != nil {
+		log.Printf("Starting gRPC server (SDK + OTLP TraceService) on port %d", cfg.OTLPGRPCPort)
+		if err := server.grpc.Start(cfg.OTLPGRPCPort); err != nil {
 			log.Printf("gRPC server error: %v", err)
 		}
 	}()
@@ -74,6 +165,13 @@ func Start(config Config) error {
 		}
 	}()
 
+	go func() {
+		log.Printf("Starting OTLP/HTTP receiver on %s", otlpHTTPServer.Addr)
+		if err := otlpHTTPServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("OTLP/HTTP receiver error: %v", err)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -90,9 +188,27 @@ func Start(config Config) error {
 		log.Printf("HTTP server forced to shutdown: %v", err)
 	}
 
+	// Shutdown OTLP/HTTP receiver
+	if err := server.otlpHTTP.Shutdown(ctx); err != nil {
+		log.Printf("OTLP/HTTP receiver forced to shutdown: %v", err)
+	}
+
 	// Shutdown gRPC server
 	server.grpc.Stop()
 
+	// Shutdown the raft node, if clustering is enabled
+	if server.cluster != nil {
+		if err := server.cluster.Shutdown(); err != nil {
+			log.Printf("Error shutting down raft node: %v", err)
+		}
+	}
+
+	// Flush and close the telemetry provider, so no spans or metrics are
+	// lost on exit
+	if err := server.telemetry.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down telemetry provider: %v", err)
+	}
+
 	// Close storage
 	if err := server.storage.Close(); err != nil {
 		log.Printf("Error closing storage: %v", err)
@@ -109,6 +225,9 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	// Prometheus scrape endpoint for traceloop's own metrics
+	router.GET("/metrics", gin.WrapH(s.telemetry.PrometheusHandler))
+
 	// API routes
 	api := router.Group("/api/v1")
 	{
@@ -118,6 +237,17 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		api.GET("/stats", s.handleGetStats)
 	}
 
+	// Cluster admin routes, only meaningful when clustering is enabled
+	clusterGroup := router.Group("/cluster")
+	{
+		clusterGroup.POST("/join", s.handleClusterJoin)
+		clusterGroup.POST("/leave", s.handleClusterLeave)
+	}
+
+	// Internal endpoint used by followers to forward trace writes to the
+	// current raft leader; not part of the public API.
+	router.POST("/internal/store-trace", s.handleInternalStoreTrace)
+
 	// Serve static files for dashboard
 	router.Static("/static", "./web/dashboard/build/static")
 	router.StaticFile("/", "./web/dashboard/build/index.html")
@@ -128,7 +258,27 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 
 // HTTP handlers
 func (s *Server) handleGetTraces(c *gin.Context) {
-	traces, err := s.storage.GetTraces(context.Background(), 100)
+	query := storage.Query{
+		Service: c.Query("service"),
+		Limit:   100,
+	}
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		query.Limit = limit
+	}
+	if start, err := time.Parse(time.RFC3339, c.Query("start")); err == nil {
+		query.Start = start
+	}
+	if end, err := time.Parse(time.RFC3339, c.Query("end")); err == nil {
+		query.End = end
+	}
+	if attr := c.Query("attr"); attr != "" {
+		if key, value, ok := strings.Cut(attr, "="); ok {
+			query.Attributes = map[string]string{key: value}
+		}
+	}
+
+	traces, err := s.storage.GetTraces(context.Background(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -143,7 +293,7 @@ func (s *Server) handleStoreTrace(c *gin.Context) {
 		return
 	}
 
-	if err := s.storage.StoreTrace(context.Background(), trace); err != nil {
+	if err := s.StoreTrace(c.Request.Context(), trace); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -151,6 +301,116 @@ func (s *Server) handleStoreTrace(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "stored"})
 }
 
+// handleInternalStoreTrace is the target of leader-forwarding: a
+// follower that received a write forwards it here, to whichever node it
+// believes is the current leader.
+func (s *Server) handleInternalStoreTrace(c *gin.Context) {
+	var trace map[string]interface{}
+	if err := c.ShouldBindJSON(&trace); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.StoreTrace(c.Request.Context(), trace); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "stored"})
+}
+
+func (s *Server) handleClusterJoin(c *gin.Context) {
+	if s.cluster == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "clustering is not enabled"})
+		return
+	}
+
+	var req struct {
+		NodeID   string `json:"node_id"`
+		RaftAddr string `json:"raft_addr"`
+		APIAddr  string `json:"api_addr"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.cluster.Join(req.NodeID, req.RaftAddr, req.APIAddr); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "joined"})
+}
+
+func (s *Server) handleClusterLeave(c *gin.Context) {
+	if s.cluster == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "clustering is not enabled"})
+		return
+	}
+
+	var req struct {
+		NodeID string `json:"node_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.cluster.Leave(req.NodeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "left"})
+}
+
+// StoreTrace implements otlp.Writer. With clustering disabled it stores
+// directly; with clustering enabled it applies the write through Raft
+// consensus if this node is the leader, or forwards it to whichever node
+// is, so any node can accept traces.
+func (s *Server) StoreTrace(ctx context.Context, trace map[string]interface{}) error {
+	if s.cluster == nil {
+		return s.storage.StoreTrace(ctx, trace)
+	}
+
+	if s.cluster.IsLeader() {
+		return s.cluster.Apply(ctx, trace)
+	}
+
+	leaderAddr, ok := s.cluster.LeaderAPIAddr()
+	if !ok {
+		return fmt.Errorf("no raft leader available")
+	}
+	return forwardStoreTrace(ctx, leaderAddr, trace)
+}
+
+// forwardStoreTrace forwards trace to the /internal/store-trace endpoint
+// of the node at apiAddr, which is expected to be the current leader.
+func forwardStoreTrace(ctx context.Context, apiAddr string, trace map[string]interface{}) error {
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return fmt.Errorf("failed to encode trace for forwarding: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s/internal/store-trace", apiAddr), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build forward request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to forward trace to leader %s: %w", apiAddr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("leader %s rejected forwarded trace (%s)", apiAddr, resp.Status)
+	}
+	return nil
+}
+
 func (s *Server) handleGetTrace(c *gin.Context) {
 	id := c.Param("id")
 	trace, err := s.storage.GetTrace(context.Background(), id)
@@ -167,5 +427,10 @@ func (s *Server) handleGetStats(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	sampled, dropped := s.sampler.Stats.Snapshot()
+	stats["sampling_sampled"] = sampled
+	stats["sampling_dropped"] = dropped
+
 	c.JSON(http.StatusOK, stats)
 }
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+func init() {
+	Register("badger", func(dsn string) (Store, error) {
+		return NewBadgerStore(dsn)
+	})
+}
+
+// defaultLimit is used when a Query doesn't specify one.
+const defaultLimit = 100
+
+// BadgerStore implements the Store interface using BadgerDB. It has no
+// concept of secondary indexes, so apart from a lookup by trace ID every
+// query scans the trace keyspace with the filters applied in Go. It is
+// meant for local development and small deployments; use the
+// elasticsearch or clickhouse drivers for production trace volumes.
+type BadgerStore struct {
+	db *badger.DB
+}
+
+// NewBadgerStore creates a new BadgerDB-based store
+func NewBadgerStore(dataDir string) (*BadgerStore, error) {
+	opts := badger.DefaultOptions(filepath.Join(dataDir, "traceloop"))
+	opts.Logger = nil // Disable BadgerDB logging for now
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open BadgerDB: %w", err)
+	}
+
+	return &BadgerStore{db: db}, nil
+}
+
+// Backup streams BadgerDB's native backup format (every version of every
+// key) to w. It is used both by the admin "backup" command and to take
+// Raft snapshots in clustered mode, so a new follower can catch up from
+// one snapshot instead of replaying every historical span write.
+func (s *BadgerStore) Backup(w io.Writer) error {
+	_, err := s.db.Backup(w, 0)
+	return err
+}
+
+// Restore replaces the database's contents with a stream produced by
+// Backup. Badger's Load merges a backup stream into whatever is
+// already there rather than clearing it first, so existing keys are
+// dropped up front to make this an exact replacement instead of a
+// union of old and restored state.
+func (s *BadgerStore) Restore(r io.Reader) error {
+	if err := s.db.DropAll(); err != nil {
+		return fmt.Errorf("failed to clear existing data: %w", err)
+	}
+	return s.db.Load(r, 256)
+}
+
+// Close closes the store
+func (s *BadgerStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// GetTraces retrieves traces matching q from storage
+func (s *BadgerStore) GetTraces(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	if q.TraceID != "" {
+		trace, err := s.GetTrace(ctx, q.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{trace}, nil
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	var traces []map[string]interface{}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchSize = 10
+		opts.Prefix = []byte("trace:")
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid() && len(traces) < limit; it.Next() {
+			item := it.Item()
+
+			err := item.Value(func(val []byte) error {
+				var trace map[string]interface{}
+				if err := json.Unmarshal(val, &trace); err != nil {
+					return fmt.Errorf("failed to unmarshal trace: %w", err)
+				}
+
+				if matchesQuery(trace, q) {
+					traces = append(traces, trace)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traces: %w", err)
+	}
+
+	return traces, nil
+}
+
+// matchesQuery applies q's service/time-range/attribute filters to trace
+// in Go, since BadgerDB only supports prefix iteration.
+func matchesQuery(trace map[string]interface{}, q Query) bool {
+	if q.Service != "" {
+		if service, _ := trace["service"].(string); service != q.Service {
+			return false
+		}
+	}
+
+	if !q.Start.IsZero() || !q.End.IsZero() {
+		startTime, ok := trace["start_time"].(string)
+		if !ok {
+			return false
+		}
+		t, err := time.Parse(time.RFC3339Nano, startTime)
+		if err != nil {
+			return false
+		}
+		if !q.Start.IsZero() && t.Before(q.Start) {
+			return false
+		}
+		if !q.End.IsZero() && t.After(q.End) {
+			return false
+		}
+	}
+
+	if len(q.Attributes) > 0 {
+		attrs, _ := trace["attributes"].(map[string]interface{})
+		for key, want := range q.Attributes {
+			got, ok := attrs[key]
+			if !ok || fmt.Sprintf("%v", got) != want {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// GetTrace retrieves a specific trace by ID
+func (s *BadgerStore) GetTrace(ctx context.Context, id string) (map[string]interface{}, error) {
+	var trace map[string]interface{}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		key := []byte("trace:" + id)
+		item, err := txn.Get(key)
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return fmt.Errorf("trace not found")
+			}
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &trace)
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trace, nil
+}
+
+// StoreTrace stores a trace in the database
+func (s *BadgerStore) StoreTrace(ctx context.Context, trace map[string]interface{}) error {
+	// Extract trace ID
+	traceID, ok := trace["trace_id"].(string)
+	if !ok {
+		return fmt.Errorf("trace_id is required")
+	}
+
+	// Serialize trace to JSON
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	// Store in BadgerDB
+	key := []byte("trace:" + traceID)
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, data)
+	})
+}
+
+// DeleteTraces removes every trace whose start_time is before olderThan.
+func (s *BadgerStore) DeleteTraces(ctx context.Context, olderThan time.Time) (int, error) {
+	var keys [][]byte
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchSize = 10
+		opts.Prefix = []byte("trace:")
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+
+			err := item.Value(func(val []byte) error {
+				var trace map[string]interface{}
+				if err := json.Unmarshal(val, &trace); err != nil {
+					return fmt.Errorf("failed to unmarshal trace: %w", err)
+				}
+
+				startTime, ok := trace["start_time"].(string)
+				if !ok {
+					return nil
+				}
+				t, err := time.Parse(time.RFC3339Nano, startTime)
+				if err != nil || !t.Before(olderThan) {
+					return nil
+				}
+
+				keys = append(keys, item.KeyCopy(nil))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find traces to delete: %w", err)
+	}
+
+	err = s.db.Update(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete traces: %w", err)
+	}
+
+	return len(keys), nil
+}
+
+// RunValueLogGC reclaims space in BadgerDB's value log left behind by
+// overwritten and deleted entries. It is used by the admin "compact"
+// command; badger.ErrNoRewrite means there was nothing to reclaim and is
+// not treated as a failure.
+func (s *BadgerStore) RunValueLogGC(discardRatio float64) error {
+	err := s.db.RunValueLogGC(discardRatio)
+	if err != nil && err != badger.ErrNoRewrite {
+		return err
+	}
+	return nil
+}
+
+// GetStats retrieves storage statistics
+func (s *BadgerStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	var totalTraces, totalSpans int
+	var storageSize int64
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte("trace:")
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key := item.Key()
+
+			if strings.HasPrefix(string(key), "trace:") {
+				totalTraces++
+
+				// Count spans in this trace
+				err := item.Value(func(val []byte) error {
+					var trace map[string]interface{}
+					if err := json.Unmarshal(val, &trace); err != nil {
+						return err
+					}
+
+					if spans, ok := trace["spans"].([]interface{}); ok {
+						totalSpans += len(spans)
+					}
+
+					storageSize += int64(len(val))
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	// Convert storage size to human readable format
+	storageSizeMB := float64(storageSize) / (1024 * 1024)
+	storageSizeStr := fmt.Sprintf("%.2f MB", storageSizeMB)
+
+	return map[string]interface{}{
+		"total_traces": totalTraces,
+		"total_spans":  totalSpans,
+		"storage_size": storageSizeStr,
+	}, nil
+}
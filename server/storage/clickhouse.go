@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+func init() {
+	Register("clickhouse", func(dsn string) (Store, error) {
+		return NewClickHouseStore(dsn)
+	})
+}
+
+// ClickHouseStore implements Store on top of ClickHouse. Traces are kept
+// in a ReplacingMergeTree table ordered by (service, start_time, trace_id)
+// so that service and time-range filters are served by the table's
+// primary index rather than a full scan, and GetStats is a single
+// aggregation query. ReplacingMergeTree lets a trace be re-ingested (a
+// retry, a re-export) without StoreTrace needing to know whether a row
+// for its trace_id already exists; every read queries FINAL so a
+// duplicate is resolved to its most recently ingested row even before
+// ClickHouse has gotten around to merging it away in the background.
+type ClickHouseStore struct {
+	conn driver.Conn
+}
+
+// NewClickHouseStore opens a connection to the ClickHouse instance
+// described by dsn (e.g. "clickhouse://localhost:9000/traceloop") and
+// ensures the traces table exists.
+func NewClickHouseStore(dsn string) (*ClickHouseStore, error) {
+	opts, err := clickhouse.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse clickhouse dsn: %w", err)
+	}
+
+	conn, err := clickhouse.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open clickhouse connection: %w", err)
+	}
+
+	store := &ClickHouseStore{conn: conn}
+	if err := store.ensureSchema(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *ClickHouseStore) ensureSchema(ctx context.Context) error {
+	return s.conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS traces (
+			trace_id    String,
+			service     String,
+			start_time  DateTime64(9),
+			status      String,
+			spans_count UInt32,
+			attributes  Map(String, String),
+			data        String,
+			ingested_at DateTime64(9) DEFAULT now64(9)
+		) ENGINE = ReplacingMergeTree(ingested_at)
+		ORDER BY (service, start_time, trace_id)
+	`)
+}
+
+// Close closes the underlying ClickHouse connection pool.
+func (s *ClickHouseStore) Close() error {
+	return s.conn.Close()
+}
+
+// StoreTrace inserts trace as a row, alongside the indexed columns used
+// by GetTraces/GetStats so they don't have to unmarshal data to filter.
+func (s *ClickHouseStore) StoreTrace(ctx context.Context, trace map[string]interface{}) error {
+	traceID, ok := trace["trace_id"].(string)
+	if !ok {
+		return fmt.Errorf("trace_id is required")
+	}
+
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	service, _ := trace["service"].(string)
+	status, _ := trace["status"].(string)
+	startTime, _ := trace["start_time"].(string)
+
+	spansCount := spanCount(trace)
+
+	attributes := map[string]string{}
+	if attrs, ok := trace["attributes"].(map[string]interface{}); ok {
+		for k, v := range attrs {
+			attributes[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return s.conn.Exec(ctx, `
+		INSERT INTO traces (trace_id, service, start_time, status, spans_count, attributes, data)
+		VALUES (?, ?, parseDateTime64BestEffortOrZero(?, 9), ?, ?, ?, ?)
+	`, traceID, service, startTime, status, spansCount, attributes, string(data))
+}
+
+// GetTrace retrieves trace_id's row, resolving any duplicate rows left
+// by a retried StoreTrace to the most recently ingested one via FINAL.
+func (s *ClickHouseStore) GetTrace(ctx context.Context, id string) (map[string]interface{}, error) {
+	row := s.conn.QueryRow(ctx, `SELECT data FROM traces FINAL WHERE trace_id = ? LIMIT 1`, id)
+
+	var data string
+	if err := row.Scan(&data); err != nil {
+		return nil, fmt.Errorf("trace not found")
+	}
+
+	var trace map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &trace); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trace: %w", err)
+	}
+
+	return trace, nil
+}
+
+// GetTraces pushes q's service, time range and attribute filters down to
+// ClickHouse as a single SELECT instead of scanning every row.
+func (s *ClickHouseStore) GetTraces(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	if q.TraceID != "" {
+		trace, err := s.GetTrace(ctx, q.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{trace}, nil
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	where := "1 = 1"
+	args := []interface{}{}
+
+	if q.Service != "" {
+		where += " AND service = ?"
+		args = append(args, q.Service)
+	}
+	if !q.Start.IsZero() {
+		where += " AND start_time >= ?"
+		args = append(args, q.Start)
+	}
+	if !q.End.IsZero() {
+		where += " AND start_time <= ?"
+		args = append(args, q.End)
+	}
+	for key, value := range q.Attributes {
+		where += " AND attributes[?] = ?"
+		args = append(args, key, value)
+	}
+
+	args = append(args, limit)
+
+	rows, err := s.conn.Query(ctx, fmt.Sprintf(`
+		SELECT data FROM traces FINAL WHERE %s ORDER BY start_time DESC LIMIT ?
+	`, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query traces: %w", err)
+	}
+	defer rows.Close()
+
+	var traces []map[string]interface{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan trace row: %w", err)
+		}
+
+		var trace map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &trace); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trace: %w", err)
+		}
+		traces = append(traces, trace)
+	}
+
+	return traces, rows.Err()
+}
+
+// DeleteTraces issues a lightweight delete for every row with a
+// start_time before olderThan. ClickHouse applies these asynchronously,
+// so the returned count reflects the rows matched, not necessarily
+// rows already removed from disk. The count is taken with FINAL so a
+// trace re-ingested more than once is still only counted once; the
+// DELETE itself removes every duplicate row regardless.
+func (s *ClickHouseStore) DeleteTraces(ctx context.Context, olderThan time.Time) (int, error) {
+	row := s.conn.QueryRow(ctx, `SELECT count() FROM traces FINAL WHERE start_time < ?`, olderThan)
+
+	var matched uint64
+	if err := row.Scan(&matched); err != nil {
+		return 0, fmt.Errorf("failed to count traces to delete: %w", err)
+	}
+
+	if err := s.conn.Exec(ctx, `DELETE FROM traces WHERE start_time < ?`, olderThan); err != nil {
+		return 0, fmt.Errorf("failed to delete traces: %w", err)
+	}
+
+	return int(matched), nil
+}
+
+// GetStats aggregates trace/span counts and storage size entirely in
+// ClickHouse, querying FINAL so a trace re-ingested more than once
+// contributes only its latest row to the totals.
+func (s *ClickHouseStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	row := s.conn.QueryRow(ctx, `
+		SELECT count(), sum(spans_count), sum(length(data)) FROM traces FINAL
+	`)
+
+	var totalTraces, totalSpans uint64
+	var storageSize uint64
+	if err := row.Scan(&totalTraces, &totalSpans, &storageSize); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	storageSizeMB := float64(storageSize) / (1024 * 1024)
+
+	return map[string]interface{}{
+		"total_traces": totalTraces,
+		"total_spans":  totalSpans,
+		"storage_size": fmt.Sprintf("%.2f MB", storageSizeMB),
+	}, nil
+}
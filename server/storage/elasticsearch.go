@@ -0,0 +1,388 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+func init() {
+	Register("elasticsearch", func(dsn string) (Store, error) {
+		return NewElasticsearchStore(dsn)
+	})
+}
+
+const tracesIndex = "traceloop-traces"
+
+// ElasticsearchStore implements Store on top of Elasticsearch/OpenSearch,
+// indexing each trace as a document so that lookups by trace_id, service,
+// time range and attribute filters are served by the cluster rather than
+// by unmarshalling every value in Go.
+type ElasticsearchStore struct {
+	client *elasticsearch.Client
+}
+
+// NewElasticsearchStore creates a store backed by the Elasticsearch (or
+// OpenSearch) cluster reachable at the comma-separated addresses in dsn,
+// e.g. "http://localhost:9200".
+func NewElasticsearchStore(dsn string) (*ElasticsearchStore, error) {
+	cfg := elasticsearch.Config{Addresses: strings.Split(dsn, ",")}
+
+	client, err := elasticsearch.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+
+	store := &ElasticsearchStore{client: client}
+	if err := store.ensureIndex(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *ElasticsearchStore) ensureIndex() error {
+	res, err := s.client.Indices.Exists([]string{tracesIndex})
+	if err != nil {
+		return fmt.Errorf("failed to check index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		return nil
+	}
+
+	res, err = s.client.Indices.Create(tracesIndex, s.client.Indices.Create.WithBody(strings.NewReader(tracesIndexMapping)))
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to create index %s: %s", tracesIndex, res.String())
+	}
+
+	return nil
+}
+
+// tracesIndexMapping maps service and every attributes.* field as
+// keyword rather than Elasticsearch's default analyzed text, since
+// buildBoolQuery filters on them with exact-match term queries. Without
+// this, a value like "payment-service" is analyzed into the tokens
+// "payment"/"service" and a term query for the literal value never
+// matches.
+const tracesIndexMapping = `{
+	"mappings": {
+		"dynamic_templates": [
+			{
+				"attributes_as_keywords": {
+					"path_match": "attributes.*",
+					"mapping": {"type": "keyword"}
+				}
+			}
+		],
+		"properties": {
+			"trace_id": {"type": "keyword"},
+			"service": {"type": "keyword"},
+			"start_time": {"type": "date"},
+			"spans_count": {"type": "integer"}
+		}
+	}
+}`
+
+// Close closes the store. The Elasticsearch client keeps no long-lived
+// connection, so there is nothing to release.
+func (s *ElasticsearchStore) Close() error {
+	return nil
+}
+
+// StoreTrace indexes trace under its trace_id.
+func (s *ElasticsearchStore) StoreTrace(ctx context.Context, trace map[string]interface{}) error {
+	traceID, ok := trace["trace_id"].(string)
+	if !ok {
+		return fmt.Errorf("trace_id is required")
+	}
+
+	// Denormalize the span count onto the document so GetStats can sum
+	// an indexed field instead of scripting over _source, which can't
+	// see array lengths for fields that aren't mapped as nested.
+	trace["spans_count"] = spanCount(trace)
+
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      tracesIndex,
+		DocumentID: traceID,
+		Body:       bytes.NewReader(data),
+		Refresh:    "false",
+	}
+
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		return fmt.Errorf("failed to index trace: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to index trace %s: %s", traceID, res.String())
+	}
+
+	return nil
+}
+
+// GetTrace retrieves a single trace document by trace_id.
+func (s *ElasticsearchStore) GetTrace(ctx context.Context, id string) (map[string]interface{}, error) {
+	req := esapi.GetRequest{Index: tracesIndex, DocumentID: id}
+
+	res, err := req.Do(ctx, s.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trace: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, fmt.Errorf("trace not found")
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get trace %s: %s", id, res.String())
+	}
+
+	var doc struct {
+		Source map[string]interface{} `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode trace: %w", err)
+	}
+
+	return doc.Source, nil
+}
+
+// GetTraces runs q against Elasticsearch as a single bool query, pushing
+// the trace_id, service, time range and attribute filters down to the
+// cluster instead of scanning every document.
+func (s *ElasticsearchStore) GetTraces(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	if q.TraceID != "" {
+		trace, err := s.GetTrace(ctx, q.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{trace}, nil
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"size":  limit,
+		"sort":  []map[string]interface{}{{"start_time": map[string]string{"order": "desc"}}},
+		"query": buildBoolQuery(q),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(tracesIndex),
+		s.client.Search.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search traces: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to search traces: %s", res.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source map[string]interface{} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	traces := make([]map[string]interface{}, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		traces = append(traces, hit.Source)
+	}
+
+	return traces, nil
+}
+
+func buildBoolQuery(q Query) map[string]interface{} {
+	var filters []map[string]interface{}
+
+	if q.Service != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"service": q.Service}})
+	}
+
+	if !q.Start.IsZero() || !q.End.IsZero() {
+		rng := map[string]interface{}{}
+		if !q.Start.IsZero() {
+			rng["gte"] = q.Start
+		}
+		if !q.End.IsZero() {
+			rng["lte"] = q.End
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"start_time": rng}})
+	}
+
+	for key, value := range q.Attributes {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"attributes." + key: value},
+		})
+	}
+
+	if len(filters) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": filters}}
+}
+
+// GetStats runs a single aggregation query over the traces index,
+// letting Elasticsearch compute totals rather than unmarshalling every
+// document in Go.
+func (s *ElasticsearchStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"aggs": map[string]interface{}{
+			"total_spans": map[string]interface{}{
+				"sum": map[string]interface{}{"field": "spans_count"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build stats query: %w", err)
+	}
+
+	res, err := s.client.Search(
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithIndex(tracesIndex),
+		s.client.Search.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get stats: %s", res.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			TotalSpans struct {
+				Value float64 `json:"value"`
+			} `json:"total_spans"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode stats response: %w", err)
+	}
+
+	storageSizeStr, err := s.indexStoreSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"total_traces": result.Hits.Total.Value,
+		"total_spans":  int(result.Aggregations.TotalSpans.Value),
+		"storage_size": storageSizeStr,
+	}, nil
+}
+
+// DeleteTraces deletes every document with a start_time before olderThan
+// using Elasticsearch's delete-by-query API.
+func (s *ElasticsearchStore) DeleteTraces(ctx context.Context, olderThan time.Time) (int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"start_time": map[string]interface{}{"lt": olderThan},
+			},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to build delete query: %w", err)
+	}
+
+	res, err := s.client.DeleteByQuery(
+		[]string{tracesIndex},
+		bytes.NewReader(body),
+		s.client.DeleteByQuery.WithContext(ctx),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete traces: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("failed to delete traces: %s", res.String())
+	}
+
+	var result struct {
+		Deleted int `json:"deleted"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode delete response: %w", err)
+	}
+
+	return result.Deleted, nil
+}
+
+// indexStoreSize asks Elasticsearch for the on-disk size of the traces
+// index via the indices stats API.
+func (s *ElasticsearchStore) indexStoreSize(ctx context.Context) (string, error) {
+	res, err := s.client.Indices.Stats(
+		s.client.Indices.Stats.WithContext(ctx),
+		s.client.Indices.Stats.WithIndex(tracesIndex),
+		s.client.Indices.Stats.WithMetric("store"),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get index stats: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", fmt.Errorf("failed to get index stats: %s", res.String())
+	}
+
+	var stats struct {
+		Indices map[string]struct {
+			Total struct {
+				Store struct {
+					SizeInBytes int64 `json:"size_in_bytes"`
+				} `json:"store"`
+			} `json:"total"`
+		} `json:"indices"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		return "", fmt.Errorf("failed to decode index stats: %w", err)
+	}
+
+	sizeBytes := stats.Indices[tracesIndex].Total.Store.SizeInBytes
+	return fmt.Sprintf("%.2f MB", float64(sizeBytes)/(1024*1024)), nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/traceloop-ai/traceloop/server/storage"
+
+// instrumentedStore wraps a Store with tracing spans and metrics for
+// every operation, so any backend can be observed the same way
+// regardless of the driver selected at startup. It reads the global otel
+// tracer/meter providers, so it works whether or not self-instrumentation
+// is enabled: real exporters when it is, otel's no-op providers otherwise.
+type instrumentedStore struct {
+	Store
+	driver string
+
+	tracer trace.Tracer
+
+	ingested   metric.Int64Counter
+	latency    metric.Float64Histogram
+	queueDepth metric.Int64UpDownCounter
+}
+
+// Unwrap returns the Store passed to Instrumented, so callers that need
+// to type-assert against a concrete backend (e.g. admin commands that
+// only work against BadgerStore) can see past the decorator.
+func (s *instrumentedStore) Unwrap() Store {
+	return s.Store
+}
+
+// Unwrap returns store's underlying Store if it was wrapped by
+// Instrumented, or store itself otherwise.
+func Unwrap(store Store) Store {
+	if u, ok := store.(interface{ Unwrap() Store }); ok {
+		return u.Unwrap()
+	}
+	return store
+}
+
+// Instrumented wraps store with spans and metrics, tagging them with
+// driver (the storage driver name, e.g. "badger") so dashboards can
+// break down latency and ingest rate per backend.
+func Instrumented(store Store, driver string) Store {
+	meter := otel.Meter(instrumentationName)
+
+	ingested, _ := meter.Int64Counter("traceloop.storage.traces_ingested",
+		metric.WithDescription("Number of traces written to storage"))
+	latency, _ := meter.Float64Histogram("traceloop.storage.operation_latency",
+		metric.WithDescription("Storage operation latency"),
+		metric.WithUnit("ms"))
+	queueDepth, _ := meter.Int64UpDownCounter("traceloop.storage.inflight_writes",
+		metric.WithDescription("Number of StoreTrace calls currently in flight"))
+
+	return &instrumentedStore{
+		Store:      store,
+		driver:     driver,
+		tracer:     otel.Tracer(instrumentationName),
+		ingested:   ingested,
+		latency:    latency,
+		queueDepth: queueDepth,
+	}
+}
+
+func (s *instrumentedStore) StoreTrace(ctx context.Context, t map[string]interface{}) error {
+	ctx, span := s.tracer.Start(ctx, "storage.StoreTrace")
+	defer span.End()
+
+	s.queueDepth.Add(ctx, 1, metric.WithAttributes(attribute.String("driver", s.driver)))
+	defer s.queueDepth.Add(ctx, -1, metric.WithAttributes(attribute.String("driver", s.driver)))
+
+	start := time.Now()
+	err := s.Store.StoreTrace(ctx, t)
+	s.recordLatency(ctx, "StoreTrace", start, err)
+	if err == nil {
+		s.ingested.Add(ctx, 1, metric.WithAttributes(attribute.String("driver", s.driver)))
+	}
+	return err
+}
+
+func (s *instrumentedStore) GetTrace(ctx context.Context, id string) (map[string]interface{}, error) {
+	ctx, span := s.tracer.Start(ctx, "storage.GetTrace")
+	defer span.End()
+
+	start := time.Now()
+	t, err := s.Store.GetTrace(ctx, id)
+	s.recordLatency(ctx, "GetTrace", start, err)
+	return t, err
+}
+
+func (s *instrumentedStore) GetTraces(ctx context.Context, q Query) ([]map[string]interface{}, error) {
+	ctx, span := s.tracer.Start(ctx, "storage.GetTraces")
+	defer span.End()
+
+	start := time.Now()
+	traces, err := s.Store.GetTraces(ctx, q)
+	s.recordLatency(ctx, "GetTraces", start, err)
+	return traces, err
+}
+
+func (s *instrumentedStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	ctx, span := s.tracer.Start(ctx, "storage.GetStats")
+	defer span.End()
+
+	start := time.Now()
+	stats, err := s.Store.GetStats(ctx)
+	s.recordLatency(ctx, "GetStats", start, err)
+	return stats, err
+}
+
+func (s *instrumentedStore) DeleteTraces(ctx context.Context, olderThan time.Time) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "storage.DeleteTraces")
+	defer span.End()
+
+	start := time.Now()
+	deleted, err := s.Store.DeleteTraces(ctx, olderThan)
+	s.recordLatency(ctx, "DeleteTraces", start, err)
+	return deleted, err
+}
+
+func (s *instrumentedStore) recordLatency(ctx context.Context, op string, start time.Time, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("driver", s.driver),
+		attribute.String("operation", op),
+		attribute.Bool("error", err != nil),
+	}
+	s.latency.Record(ctx, float64(time.Since(start).Milliseconds()), metric.WithAttributes(attrs...))
+}
@@ -2,191 +2,75 @@ package storage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"path/filepath"
-
-	"github.com/dgraph-io/badger/v4"
+	"time"
 )
 
+// Query describes the filters applied when listing traces. Backends that
+// support indexed lookups (Elasticsearch, ClickHouse) should push these
+// filters down to the database instead of scanning and filtering in Go.
+type Query struct {
+	TraceID    string
+	Service    string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Limit      int
+}
+
 // Store interface defines the storage operations
 type Store interface {
-	GetTraces(ctx context.Context, limit int) ([]map[string]interface{}, error)
+	GetTraces(ctx context.Context, q Query) ([]map[string]interface{}, error)
 	GetTrace(ctx context.Context, id string) (map[string]interface{}, error)
 	GetStats(ctx context.Context) (map[string]interface{}, error)
 	StoreTrace(ctx context.Context, trace map[string]interface{}) error
+	// DeleteTraces removes every trace whose start_time is before
+	// olderThan, returning the number of traces deleted.
+	DeleteTraces(ctx context.Context, olderThan time.Time) (int, error)
 	Close() error
 }
 
-// BadgerStore implements the Store interface using BadgerDB
-type BadgerStore struct {
-	db *badger.DB
-}
-
-// NewBadgerStore creates a new BadgerDB-based store
-func NewBadgerStore(dataDir string) (*BadgerStore, error) {
-	opts := badger.DefaultOptions(filepath.Join(dataDir, "traceloop"))
-	opts.Logger = nil // Disable BadgerDB logging for now
+// Factory creates a Store from a driver-specific DSN (a filesystem path
+// for badger, a comma-separated address list for elasticsearch, a
+// connection string for clickhouse, etc).
+type Factory func(dsn string) (Store, error)
 
-	db, err := badger.Open(opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open BadgerDB: %w", err)
-	}
-
-	return &BadgerStore{db: db}, nil
-}
+var drivers = make(map[string]Factory)
 
-// Close closes the store
-func (s *BadgerStore) Close() error {
-	if s.db != nil {
-		return s.db.Close()
+// Register registers a storage driver factory under name, so it can be
+// selected at startup via the --storage flag. Drivers register themselves
+// from an init() function in their own file.
+func Register(name string, factory Factory) {
+	if _, exists := drivers[name]; exists {
+		panic(fmt.Sprintf("storage: driver %q already registered", name))
 	}
-	return nil
+	drivers[name] = factory
 }
 
-// GetTraces retrieves traces from storage
-func (s *BadgerStore) GetTraces(ctx context.Context, limit int) ([]map[string]interface{}, error) {
-	var traces []map[string]interface{}
-
-	err := s.db.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		opts.PrefetchSize = 10
-		opts.Prefix = []byte("trace:")
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		count := 0
-		for it.Rewind(); it.Valid() && count < limit; it.Next() {
-			item := it.Item()
-			key := item.Key()
-
-			// Only process trace keys
-			if string(key[:6]) == "trace:" {
-				err := item.Value(func(val []byte) error {
-					// Parse the stored trace data
-					var trace map[string]interface{}
-					if err := json.Unmarshal(val, &trace); err != nil {
-						return fmt.Errorf("failed to unmarshal trace: %w", err)
-					}
-					traces = append(traces, trace)
-					count++
-					return nil
-				})
-				if err != nil {
-					return err
-				}
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get traces: %w", err)
-	}
-
-	return traces, nil
-}
-
-// GetTrace retrieves a specific trace by ID
-func (s *BadgerStore) GetTrace(ctx context.Context, id string) (map[string]interface{}, error) {
-	var trace map[string]interface{}
-
-	err := s.db.View(func(txn *badger.Txn) error {
-		key := []byte("trace:" + id)
-		item, err := txn.Get(key)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return fmt.Errorf("trace not found")
-			}
-			return err
-		}
-
-		return item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &trace)
-		})
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return trace, nil
-}
-
-// StoreTrace stores a trace in the database
-func (s *BadgerStore) StoreTrace(ctx context.Context, trace map[string]interface{}) error {
-	// Extract trace ID
-	traceID, ok := trace["trace_id"].(string)
+// New creates a Store for the named driver, passing dsn through to its
+// factory.
+func New(name, dsn string) (Store, error) {
+	factory, ok := drivers[name]
 	if !ok {
-		return fmt.Errorf("trace_id is required")
+		return nil, fmt.Errorf("storage: unknown driver %q (forgot to import it?)", name)
 	}
-
-	// Serialize trace to JSON
-	data, err := json.Marshal(trace)
-	if err != nil {
-		return fmt.Errorf("failed to marshal trace: %w", err)
-	}
-
-	// Store in BadgerDB
-	key := []byte("trace:" + traceID)
-	return s.db.Update(func(txn *badger.Txn) error {
-		return txn.Set(key, data)
-	})
+	return factory(dsn)
 }
 
-// GetStats retrieves storage statistics
-func (s *BadgerStore) GetStats(ctx context.Context) (map[string]interface{}, error) {
-	var totalTraces, totalSpans int
-	var storageSize int64
-
-	err := s.db.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		opts.Prefix = []byte("trace:")
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		for it.Rewind(); it.Valid(); it.Next() {
-			item := it.Item()
-			key := item.Key()
-
-			if string(key[:6]) == "trace:" {
-				totalTraces++
-
-				// Count spans in this trace
-				err := item.Value(func(val []byte) error {
-					var trace map[string]interface{}
-					if err := json.Unmarshal(val, &trace); err != nil {
-						return err
-					}
-
-					if spans, ok := trace["spans"].([]interface{}); ok {
-						totalSpans += len(spans)
-					}
-
-					storageSize += int64(len(val))
-					return nil
-				})
-				if err != nil {
-					return err
-				}
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get stats: %w", err)
+// spanCount returns the number of entries in trace's "spans" field, used
+// by backends (elasticsearch, clickhouse) that store a denormalized
+// span count alongside a trace so GetStats can sum it instead of
+// unmarshalling every document. trace["spans"] is []map[string]interface{}
+// for traces freshly produced by otlp.ConvertResourceSpans, but decodes
+// as []interface{} once it has round-tripped through JSON (e.g. after
+// being read back from a backend), so both are handled.
+func spanCount(trace map[string]interface{}) int {
+	switch spans := trace["spans"].(type) {
+	case []map[string]interface{}:
+		return len(spans)
+	case []interface{}:
+		return len(spans)
+	default:
+		return 0
 	}
-
-	// Convert storage size to human readable format
-	storageSizeMB := float64(storageSize) / (1024 * 1024)
-	storageSizeStr := fmt.Sprintf("%.2f MB", storageSizeMB)
-
-	return map[string]interface{}{
-		"total_traces": totalTraces,
-		"total_spans":  totalSpans,
-		"storage_size": storageSizeStr,
-	}, nil
 }
@@ -21,7 +21,7 @@ func TestBadgerStore_GetTraces(t *testing.T) {
 	}()
 
 	// Test getting traces from empty store
-	traces, err := store.GetTraces(context.Background(), 10)
+	traces, err := store.GetTraces(context.Background(), Query{Limit: 10})
 	if err != nil {
 		t.Fatalf("Failed to get traces: %v", err)
 	}
@@ -0,0 +1,20 @@
+// Package telemetry wires traceloop's own OpenTelemetry tracer and meter
+// providers, so operators can monitor the traceloop server itself with
+// the same tooling they use for the agents whose traces it collects.
+package telemetry
+
+// Config configures self-instrumentation, populated from the "trace"
+// block of the server's YAML config file.
+type Config struct {
+	Enabled    bool    `yaml:"enabled"`
+	SampleRate float64 `yaml:"sample_rate"`
+	// Exporter selects where spans are sent: "jaeger" or "otlp".
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the exporter's collector address.
+	Endpoint string `yaml:"endpoint"`
+	// Namespace names this deployment in the emitted resource attributes
+	// (service.namespace).
+	Namespace string `yaml:"namespace"`
+	// Attributes are additional service-level resource attributes.
+	Attributes map[string]string `yaml:"attributes"`
+}
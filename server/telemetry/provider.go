@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies the traceloop server itself in emitted traces
+// and metrics, independent of the services whose traces it collects.
+const ServiceName = "traceloop-server"
+
+// Provider holds traceloop's own tracer and meter providers. Instrumented
+// call sites (the gin router, the gRPC server, BadgerStore) always read
+// from the global otel providers, so they work whether or not
+// self-instrumentation is enabled: New installs real exporters when it
+// is, and otel's no-op providers otherwise.
+type Provider struct {
+	cfg Config
+
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+
+	// PrometheusHandler serves the /metrics scrape endpoint. It is always
+	// non-nil; when telemetry is disabled it answers 404 Not Found.
+	PrometheusHandler http.Handler
+
+	Tracer trace.Tracer
+	Meter  metric.Meter
+}
+
+// New builds a Provider from cfg and installs it as the global otel
+// tracer/meter providers. When cfg.Enabled is false, New leaves the
+// global otel no-op providers in place and returns a Provider whose
+// Shutdown is a no-op.
+func New(cfg Config) (*Provider, error) {
+	if !cfg.Enabled {
+		return &Provider{
+			cfg:               cfg,
+			PrometheusHandler: http.NotFoundHandler(),
+			Tracer:            otel.Tracer(ServiceName),
+			Meter:             otel.Meter(ServiceName),
+		}, nil
+	}
+
+	res, err := buildResource(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+
+	exporter, err := newSpanExporter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s span exporter: %w", cfg.Exporter, err)
+	}
+
+	sampler := sdktrace.TraceIDRatioBased(cfg.SampleRate)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+
+	promExporter, err := prometheus.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(promExporter),
+		sdkmetric.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	return &Provider{
+		cfg:               cfg,
+		tracerProvider:    tp,
+		meterProvider:     mp,
+		PrometheusHandler: promhttp.Handler(),
+		Tracer:            tp.Tracer(ServiceName),
+		Meter:             mp.Meter(ServiceName),
+	}, nil
+}
+
+func buildResource(cfg Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(ServiceName),
+	}
+	if cfg.Namespace != "" {
+		attrs = append(attrs, semconv.ServiceNamespace(cfg.Namespace))
+	}
+	for k, v := range cfg.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+}
+
+func newSpanExporter(cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "jaeger":
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.Endpoint)))
+	case "otlp", "":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// Shutdown flushes and closes the tracer and meter providers so no spans
+// or metrics are lost on exit. It is safe to call even when telemetry was
+// never enabled.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tracerProvider != nil {
+		if err := p.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down tracer provider: %w", err)
+		}
+	}
+	if p.meterProvider != nil {
+		if err := p.meterProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down meter provider: %w", err)
+		}
+	}
+	return nil
+}